@@ -3,9 +3,9 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	_ "github.com/lib/pq"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 	"log/slog"
 	"net"
@@ -14,8 +14,12 @@ import (
 	"os/signal"
 	"subscription/internal/config"
 	"subscription/internal/handler"
+	"subscription/internal/middleware/audit"
 	mwLogger "subscription/internal/middleware/logger"
+	"subscription/internal/repository/memory"
+	"subscription/internal/repository/mysql"
 	"subscription/internal/repository/postgres"
+	"subscription/internal/repository/sqlite"
 	"subscription/internal/service"
 	"subscription/migrations"
 	"syscall"
@@ -40,7 +44,7 @@ func main() {
 	}
 
 	// 2) repo
-	repo, err := postgres.NewPostgresDB(cfg)
+	repo, err := newStorage(cfg)
 	if err != nil {
 		logger.Error("db connect failed", slog.String("error", err.Error()))
 		os.Exit(1)
@@ -53,14 +57,23 @@ func main() {
 
 	// 3) services
 	services := service.NewSubscriptionService(repo, logger, cfg)
+	idempotency := service.NewIdempotencyService(repo, logger, cfg)
 	h := handler.NewHandler(services, logger)
 
+	if cfg.FX.RatesFile != "" {
+		if err := importFXRates(services, cfg.FX.RatesFile, logger); err != nil {
+			logger.Error("fx rates import failed", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
 	// 4) router + middleware
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
 	r.Use(mwLogger.New(logger))
+	r.Use(audit.New())
 	r.Use(middleware.Timeout(cfg.HTTPServer.Timeout))
 
 	// healthz
@@ -78,12 +91,39 @@ func main() {
 	))
 
 	// 6) API
-	r.Post("/subscriptions", h.CreateSubscription)
+	r.With(h.Idempotent(idempotency)).Post("/subscriptions", h.CreateSubscription)
 	r.Get("/subscriptions/{id}", h.GetSubscription)
 	r.Get("/subscriptions", h.ListSubscriptions)
 	r.Put("/subscriptions/{id}", h.UpdateSubscription)
+	r.Patch("/subscriptions/{id}", h.PatchSubscription)
 	r.Delete("/subscriptions/{id}", h.DeleteSubscription)
+	r.Post("/subscriptions/{id}/restore", h.RestoreSubscription)
+	r.Get("/subscriptions/{id}/history", h.SubscriptionHistory)
+	r.Post("/subscriptions/{id}/prices", h.SchedulePriceChange)
+	r.Get("/subscriptions/{id}/prices", h.PriceSchedule)
 	r.Get("/subscriptions/summary", h.SumSubscriptions)
+	r.Get("/fx-rates", h.ListFXRates)
+	r.Post("/fx-rates/import", h.ImportFXRates)
+	r.Put("/fx-rates/{base}/{quote}/{valid_from}", h.SetFXRate)
+	r.Delete("/fx-rates/{base}/{quote}/{valid_from}", h.DeleteFXRate)
+	r.Get("/services", h.ListServices)
+	r.Post("/services", h.CreateService)
+	r.Get("/services/{id}", h.GetService)
+	r.Put("/services/{id}", h.UpdateService)
+	r.Delete("/services/{id}", h.DeleteService)
+	r.Get("/services/{id}/plans", h.ListPlans)
+	r.Post("/services/{id}/plans", h.CreatePlan)
+	r.Get("/services/{id}/plans/{plan_id}", h.GetPlan)
+	r.Put("/services/{id}/plans/{plan_id}", h.UpdatePlan)
+	r.Delete("/services/{id}/plans/{plan_id}", h.DeletePlan)
+	r.Get("/budgets", h.ListBudgets)
+	r.Post("/budgets", h.CreateBudget)
+	r.Get("/budgets/{id}", h.GetBudget)
+	r.Put("/budgets/{id}", h.UpdateBudget)
+	r.Delete("/budgets/{id}", h.DeleteBudget)
+	r.Get("/budgets/{id}/status", h.BudgetStatus)
+	r.Get("/budgets/{id}/alerts", h.BudgetAlerts)
+	r.Get("/users/{user_id}/renewals", h.Renewals)
 
 	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "resource not found", http.StatusNotFound)
@@ -111,6 +151,12 @@ func main() {
 	// Ожидаем сигнал и красиво гасим сервер
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
+
+	// Фоновые очистки: истёкшие ключи идемпотентности и давно удалённые подписки.
+	// Останавливаются вместе с сервером
+	go idempotency.RunSweeper(ctx, cfg.Idempotency.SweepInterval)
+	go services.RunPurger(ctx, cfg.SoftDelete.PurgeInterval)
+
 	<-ctx.Done()
 
 	shCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
@@ -119,10 +165,65 @@ func main() {
 	if err := srv.Shutdown(shCtx); err != nil {
 		logger.Error("server shutdown error", slog.String("error", err.Error()))
 	}
+	// Запросы завершены — новых уведомлений бюджетов не будет, дожидаемся начатых
+	if err := services.WaitWebhooks(shCtx); err != nil {
+		logger.Error("budget webhooks not finished", slog.String("error", err.Error()))
+	}
 	logger.Info("server stopped")
 
 }
 
+// storage — хранилище подписок и ключей идемпотентности, которое нужно закрыть при остановке.
+type storage interface {
+	service.SubscriptionRepository
+	service.IdempotencyRepository
+	Close() error
+}
+
+// importFXRates загружает курсы валют из CSV-файла, чтобы пересчёт сумм работал без доступа в сеть.
+func importFXRates(services *service.SubscriptionSvc, path string, logger *slog.Logger) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	n, err := services.ImportFXRates(context.Background(), f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	logger.Info("fx rates imported", slog.String("file", path), slog.Int("count", n))
+	return nil
+}
+
+// newStorage выбирает реализацию хранилища по database.driver.
+func newStorage(cfg *config.Config) (storage, error) {
+	switch cfg.Database.Driver {
+	case config.DriverPostgres:
+		repo, err := postgres.NewPostgresDB(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	case config.DriverMySQL:
+		repo, err := mysql.NewMySQLDB(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	case config.DriverSQLite:
+		repo, err := sqlite.NewSQLiteDB(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	case config.DriverMemory:
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %q", cfg.Database.Driver)
+	}
+}
+
 func setupLogger(env string) *slog.Logger {
 
 	var log *slog.Logger
@@ -9,9 +9,13 @@ import (
 )
 
 type Config struct {
-	Env        string     `yaml:"env" env:"ENV" env-default:"local"`
-	HTTPServer HTTPServer `yaml:"http_server"`
-	Database   Database   `yaml:"database"`
+	Env         string      `yaml:"env" env:"ENV" env-default:"local"`
+	HTTPServer  HTTPServer  `yaml:"http_server"`
+	Database    Database    `yaml:"database"`
+	Idempotency Idempotency `yaml:"idempotency"`
+	SoftDelete  SoftDelete  `yaml:"soft_delete"`
+	FX          FX          `yaml:"fx"`
+	Budgets     Budgets     `yaml:"budgets"`
 }
 
 type HTTPServer struct {
@@ -23,20 +27,58 @@ type HTTPServer struct {
 }
 
 type Database struct {
-	Driver   string  `yaml:"driver"   env:"DB_DRIVER"   env-default:"postgres"` // postgres | mysql - задел на будущее
+	Driver   string  `yaml:"driver"   env:"DB_DRIVER"   env-default:"postgres"` // postgres | mysql | sqlite | memory
 	Host     string  `yaml:"host"     env:"DB_HOST"     env-default:"localhost"`
 	Port     string  `yaml:"port"     env:"DB_PORT"     env-default:"5432"`
 	User     string  `yaml:"user"     env:"DB_USER"     env-default:"postgres"`
 	Password string  `yaml:"password" env:"DB_PASSWORD" env-default:"postgres"`
 	Name     string  `yaml:"name"     env:"DB_NAME"     env-default:"subscriptions"`
 	SSLMode  string  `yaml:"sslmode"  env:"DB_SSLMODE"  env-default:"disable"`
-	Pool     *DBPool `yaml:"pool,omitempty"` // nil, если секции database.pool нет
+	Path     string  `yaml:"path"     env:"DB_PATH"     env-default:"subscriptions.db"` // файл БД для sqlite
+	Pool     *DBPool `yaml:"pool,omitempty"`                                            // nil, если секции database.pool нет
 }
 
+// Idempotency — хранение ответов на запросы с Idempotency-Key.
+type Idempotency struct {
+	TTL           time.Duration `yaml:"ttl"            env:"IDEMPOTENCY_TTL"            env-default:"24h"` // сколько повторы получают сохранённый ответ
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"IDEMPOTENCY_SWEEP_INTERVAL" env-default:"10m"` // как часто удалять истёкшие ключи
+}
+
+// SoftDelete — хранение мягко удалённых подписок.
+type SoftDelete struct {
+	Retention     time.Duration `yaml:"retention"      env:"SOFT_DELETE_RETENTION"      env-default:"720h"` // через сколько удалённая подписка стирается окончательно; 0 — никогда
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"SOFT_DELETE_PURGE_INTERVAL" env-default:"1h"`   // как часто запускать очистку
+}
+
+// FX — курсы валют для пересчёта сумм.
+type FX struct {
+	RatesFile string `yaml:"rates_file" env:"FX_RATES_FILE"` // CSV с курсами, загружается при старте; пусто — не загружать
+}
+
+// Budgets — уведомления о достижении порогов бюджетов.
+type Budgets struct {
+	WebhookURL     string        `yaml:"webhook_url"     env:"BUDGETS_WEBHOOK_URL"`                      // куда отправлять новые уведомления POST-запросом; пусто — не отправлять
+	WebhookTimeout time.Duration `yaml:"webhook_timeout" env:"BUDGETS_WEBHOOK_TIMEOUT" env-default:"5s"` // таймаут одной отправки
+}
+
+// Драйверы хранилища (Database.Driver).
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory" // всё в памяти процесса, без миграций; для локальной разработки и демо
+)
+
+// DBPool — настройки пула соединений. Postgres (pgxpool) использует все поля, кроме MaxIdleConns;
+// MySQL (database/sql) — MaxOpenConns, MaxIdleConns и ConnLifetime.
 type DBPool struct {
-	MaxOpenConns int           `yaml:"max_open_conns"  env:"DB_POOL_MAX_OPEN_CONNS"  env-default:"10"`
-	MaxIdleConns int           `yaml:"max_idle_conns"  env:"DB_POOL_MAX_IDLE_CONNS"  env-default:"5"`
-	ConnLifetime time.Duration `yaml:"conn_lifetime"   env:"DB_POOL_CONN_LIFETIME"   env-default:"5m"`
+	MaxOpenConns           int           `yaml:"max_open_conns"           env:"DB_POOL_MAX_OPEN_CONNS"           env-default:"10"`
+	MaxIdleConns           int           `yaml:"max_idle_conns"           env:"DB_POOL_MAX_IDLE_CONNS"           env-default:"5"`
+	MinConns               int           `yaml:"min_conns"                env:"DB_POOL_MIN_CONNS"                env-default:"0"`
+	ConnLifetime           time.Duration `yaml:"conn_lifetime"            env:"DB_POOL_CONN_LIFETIME"            env-default:"5m"`
+	MaxConnIdleTime        time.Duration `yaml:"max_conn_idle_time"       env:"DB_POOL_MAX_CONN_IDLE_TIME"       env-default:"30m"`
+	HealthCheckPeriod      time.Duration `yaml:"health_check_period"      env:"DB_POOL_HEALTH_CHECK_PERIOD"      env-default:"1m"`
+	StatementCacheCapacity int           `yaml:"statement_cache_capacity" env:"DB_POOL_STATEMENT_CACHE_CAPACITY" env-default:"512"`
 }
 
 const defaultConfig = "./config/config.yaml"
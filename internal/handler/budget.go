@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"subscription/internal/model"
+	"time"
+)
+
+// budgetRequest — тело POST и PUT /budgets. В PUT user_id не меняется и игнорируется.
+type budgetRequest struct {
+	UserID   string `json:"user_id"`
+	Category string `json:"category"`
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (req budgetRequest) budget(id int) model.Budget {
+	return model.Budget{ID: id, UserID: req.UserID, Category: req.Category, Amount: req.Amount, Currency: req.Currency}
+}
+
+// ListBudgets отдаёт бюджеты, с ?user_id= — только бюджеты пользователя.
+func (h *Handler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	budgets, err := h.services.ListBudgets(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		h.log.Error("list budgets error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, budgets)
+}
+
+func (h *Handler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.budgetID(w, r)
+	if !ok {
+		return
+	}
+	b, err := h.services.GetBudget(r.Context(), id)
+	if err != nil {
+		h.log.Error("get budget error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, b)
+}
+
+func (h *Handler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	var req budgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("invalid request", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	b, err := h.services.CreateBudget(r.Context(), req.budget(0))
+	if err != nil {
+		h.log.Error("create budget error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, b)
+}
+
+func (h *Handler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.budgetID(w, r)
+	if !ok {
+		return
+	}
+	var req budgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("invalid request", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	b, err := h.services.UpdateBudget(r.Context(), req.budget(id))
+	if err != nil {
+		h.log.Error("update budget error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, b)
+}
+
+func (h *Handler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.budgetID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.services.DeleteBudget(r.Context(), id); err != nil {
+		h.log.Error("delete budget error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BudgetStatus отдаёт траты против бюджета за ?month=MM-YYYY, по умолчанию за текущий месяц.
+func (h *Handler) BudgetStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.budgetID(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if v := r.URL.Query().Get("month"); v != "" {
+		t, err := time.Parse(model.MonthLayout, v)
+		if err != nil {
+			h.log.Error("invalid month", "value", v, "err", err)
+			h.writeError(w, http.StatusBadRequest, "invalid month format")
+			return
+		}
+		month = t
+	}
+	status, err := h.services.BudgetStatus(r.Context(), id, month)
+	if err != nil {
+		h.log.Error("budget status error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, status)
+}
+
+// BudgetAlerts отдаёт уведомления о достигнутых порогах бюджета.
+func (h *Handler) BudgetAlerts(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.budgetID(w, r)
+	if !ok {
+		return
+	}
+	alerts, err := h.services.BudgetAlerts(r.Context(), id)
+	if err != nil {
+		h.log.Error("budget alerts error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, alerts)
+}
+
+func (h *Handler) budgetID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.log.Error("invalid id", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return id, true
+}
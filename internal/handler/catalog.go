@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"subscription/internal/model"
+)
+
+// serviceRequest — тело POST и PUT /services.
+type serviceRequest struct {
+	Name      string   `json:"name"`
+	Aliases   []string `json:"aliases"`
+	Category  string   `json:"category"`
+	VendorURL string   `json:"vendor_url"`
+}
+
+func (req serviceRequest) service(id int) model.Service {
+	return model.Service{ID: id, Name: req.Name, Aliases: req.Aliases, Category: req.Category, VendorURL: req.VendorURL}
+}
+
+// ListServices отдаёт каталог сервисов.
+func (h *Handler) ListServices(w http.ResponseWriter, r *http.Request) {
+	services, err := h.services.ListServices(r.Context())
+	if err != nil {
+		h.log.Error("list services error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, services)
+}
+
+func (h *Handler) GetService(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.serviceID(w, r)
+	if !ok {
+		return
+	}
+	svc, err := h.services.GetService(r.Context(), id)
+	if err != nil {
+		h.log.Error("get service error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, svc)
+}
+
+func (h *Handler) CreateService(w http.ResponseWriter, r *http.Request) {
+	var req serviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("invalid request", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	svc, err := h.services.CreateService(r.Context(), req.service(0))
+	if err != nil {
+		h.log.Error("create service error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, svc)
+}
+
+// UpdateService перезаписывает сервис целиком, включая список псевдонимов.
+func (h *Handler) UpdateService(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.serviceID(w, r)
+	if !ok {
+		return
+	}
+	var req serviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("invalid request", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	svc, err := h.services.UpdateService(r.Context(), req.service(id))
+	if err != nil {
+		h.log.Error("update service error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, svc)
+}
+
+func (h *Handler) DeleteService(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.serviceID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.services.DeleteService(r.Context(), id); err != nil {
+		h.log.Error("delete service error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) serviceID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.log.Error("invalid id", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"subscription/internal/model"
+	"time"
+)
+
+// ListFXRates отдаёт все курсы валют.
+func (h *Handler) ListFXRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.services.ListFXRates(r.Context())
+	if err != nil {
+		h.log.Error("list fx rates error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, rates)
+}
+
+// SetFXRate записывает курс пары {base}/{quote} с даты {valid_from}.
+func (h *Handler) SetFXRate(w http.ResponseWriter, r *http.Request) {
+	rate, ok := h.fxRateKey(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Rate float64 `json:"rate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("invalid request", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	rate.Rate = req.Rate
+
+	rate, err := h.services.SetFXRate(r.Context(), rate)
+	if err != nil {
+		h.log.Error("set fx rate error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, rate)
+}
+
+// DeleteFXRate удаляет курс пары {base}/{quote} с даты {valid_from}.
+func (h *Handler) DeleteFXRate(w http.ResponseWriter, r *http.Request) {
+	rate, ok := h.fxRateKey(w, r)
+	if !ok {
+		return
+	}
+	if err := h.services.DeleteFXRate(r.Context(), rate.Base, rate.Quote, rate.ValidFrom); err != nil {
+		h.log.Error("delete fx rate error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportFXRates загружает курсы из CSV в теле запроса.
+func (h *Handler) ImportFXRates(w http.ResponseWriter, r *http.Request) {
+	n, err := h.services.ImportFXRates(r.Context(), r.Body)
+	if err != nil {
+		h.log.Error("import fx rates error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]int{"imported": n})
+}
+
+// fxRateKey читает из пути пару валют и дату начала действия курса.
+func (h *Handler) fxRateKey(w http.ResponseWriter, r *http.Request) (model.FXRate, bool) {
+	validFrom, err := time.Parse("2006-01-02", chi.URLParam(r, "valid_from"))
+	if err != nil {
+		h.log.Error("invalid valid_from", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid valid_from format")
+		return model.FXRate{}, false
+	}
+	return model.FXRate{
+		Base:      chi.URLParam(r, "base"),
+		Quote:     chi.URLParam(r, "quote"),
+		ValidFrom: validFrom,
+	}, true
+}
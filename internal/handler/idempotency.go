@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"subscription/internal/middleware/audit"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+// IdempotencyService — контракт сервиса ключей идемпотентности для хендлеров.
+type IdempotencyService interface {
+	Begin(ctx context.Context, key, requestHash string) (*model.IdempotentResponse, error)
+	Complete(ctx context.Context, key string, resp model.IdempotentResponse) error
+	Release(ctx context.Context, key string) error
+}
+
+const (
+	headerIdempotencyKey = "Idempotency-Key"
+	// headerReplayed помечает ответ, повторённый из сохранённого.
+	headerReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+)
+
+// Idempotent — middleware для запросов с заголовком Idempotency-Key. Первый запрос
+// выполняется, его ответ сохраняется и отдаётся повторам с тем же ключом и телом.
+// Тот же ключ с другим телом — 422, повтор до завершения первого запроса — 409.
+// Ответы 5xx, запросы, упавшие с паникой, и ответы, которые не удалось сохранить, не сохраняются:
+// запрос можно повторить с тем же ключом.
+// Ключ действует в пределах клиента (см. scopedIdempotencyKey): одинаковые ключи разных
+// клиентов не мешают друг другу. Клиента называет X-Actor, поэтому без него ключ не принимается.
+func (h *Handler) Idempotent(svc IdempotencyService) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(headerIdempotencyKey)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				h.writeError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+				return
+			}
+			actor := strings.TrimSpace(r.Header.Get(audit.ActorHeader))
+			if actor == "" {
+				h.writeError(w, http.StatusBadRequest, "Idempotency-Key requires the "+audit.ActorHeader+" header")
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				h.log.Error("read body error", "err", err)
+				h.writeError(w, http.StatusBadRequest, "invalid request")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scoped := scopedIdempotencyKey(actor, key)
+			saved, err := svc.Begin(r.Context(), scoped, requestHash(r, body))
+			if err != nil {
+				h.log.Error("idempotency key error", "key", key, "err", err)
+				h.writeServiceError(w, err)
+				return
+			}
+			if saved != nil {
+				replay(w, saved)
+				return
+			}
+
+			// Ответ уже ушёл клиенту, поэтому сохраняем его, даже если запрос отменён.
+			ctx := context.WithoutCancel(r.Context())
+			release := func() {
+				if err := svc.Release(ctx, scoped); err != nil {
+					h.log.Error("release idempotency key error", "key", key, "err", err)
+				}
+			}
+			// Паника дальше обрабатывается Recoverer, но резерв ключа надо снять, иначе
+			// повторы получали бы 409 до истечения TTL.
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError {
+				release()
+				return
+			}
+			resp := model.IdempotentResponse{
+				StatusCode: rec.status,
+				Header:     rec.Header().Clone(),
+				Body:       rec.body.Bytes(),
+			}
+			if err := svc.Complete(ctx, scoped, resp); err != nil {
+				// Без сохранённого ответа повторы получали бы 409 до истечения TTL. Снимаем резерв:
+				// повтор выполнится заново, и уже созданную подписку поймает проверка пересечений.
+				h.log.Error("save idempotent response error", "key", key, "err", err)
+				release()
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// scopedIdempotencyKey — ключ, под которым хранится Idempotency-Key клиента: sha256 от X-Actor
+// и самого ключа. Тело в ключ не входит: повтор с другим телом, в том числе с другим user_id,
+// должен найти тот же ключ и получить 422 по requestHash. Длина хранимого ключа от них не зависит.
+//
+// Сервис сам клиентов не аутентифицирует: X-Actor — граница доверия, его должен выставлять
+// шлюз перед сервисом, а не сам клиент. Иначе клиент с чужим X-Actor и угаданным ключом
+// получит сохранённый чужой ответ.
+func scopedIdempotencyKey(actor, key string) string {
+	sum := sha256.New()
+	sum.Write([]byte(actor + "\n"))
+	sum.Write([]byte(key))
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// requestHash — отпечаток запроса: метод, путь и тело. JSON-тело приводится к канонической
+// форме, чтобы повтор с другими пробелами или порядком полей не считался другим запросом.
+func requestHash(r *http.Request, body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err == nil {
+		if canonical, err := json.Marshal(v); err == nil {
+			body = canonical
+		}
+	}
+	sum := sha256.New()
+	sum.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, saved *model.IdempotentResponse) {
+	for name, values := range saved.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(headerReplayed, "true")
+	w.WriteHeader(saved.StatusCode)
+	_, _ = w.Write(saved.Body)
+}
+
+// responseRecorder пропускает ответ клиенту и заодно запоминает статус и тело.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// проверяем имплиментацию
+var _ IdempotencyService = (*service.IdempotencySvc)(nil)
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"subscription/internal/config"
+	"subscription/internal/model"
+	"subscription/internal/repository/memory"
+	"subscription/internal/service"
+	"testing"
+	"time"
+)
+
+const createBody = `{"service_name":"Netflix","price_minor":49900,"user_id":"` + testUserID + `","start_date":"01-2025"}`
+
+func TestIdempotentCreate(t *testing.T) {
+	router := newTestRouter(t)
+	post := func(body string, header ...string) (int, string, string) {
+		rec := do(t, router, http.MethodPost, "/subscriptions", body, header...)
+		return rec.Code, rec.Header().Get(headerReplayed), rec.Body.String()
+	}
+
+	status, replayed, first := post(createBody, headerIdempotencyKey, "k1", "X-Actor", "alice")
+	if status != http.StatusCreated || replayed != "" {
+		t.Fatalf("first: status %d, replayed %q: %s", status, replayed, first)
+	}
+
+	// Тот же JSON с другими пробелами и порядком полей — повтор.
+	reordered := `{ "start_date":"01-2025", "user_id":"` + testUserID + `", "price_minor":49900, "service_name":"Netflix" }`
+	status, replayed, body := post(reordered, headerIdempotencyKey, "k1", "X-Actor", "alice")
+	if status != http.StatusCreated || replayed != "true" || body != first {
+		t.Errorf("replay: status %d, replayed %q, body %s; want 201, true, %s", status, replayed, body, first)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		header []string
+		want   int
+	}{
+		{
+			name:   "same key with another user_id",
+			body:   `{"service_name":"Netflix","price_minor":49900,"user_id":"223e4567-e89b-12d3-a456-426614174000","start_date":"01-2025"}`,
+			header: []string{headerIdempotencyKey, "k1", "X-Actor", "alice"},
+			want:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "same key with another price",
+			body:   `{"service_name":"Netflix","price_minor":1,"user_id":"` + testUserID + `","start_date":"01-2025"}`,
+			header: []string{headerIdempotencyKey, "k1", "X-Actor", "alice"},
+			want:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "same key from another actor",
+			body:   `{"service_name":"Spotify","price_minor":100,"user_id":"` + testUserID + `","start_date":"01-2025"}`,
+			header: []string{headerIdempotencyKey, "k1", "X-Actor", "bob"},
+			want:   http.StatusCreated,
+		},
+		{
+			name:   "key without actor",
+			body:   createBody,
+			header: []string{headerIdempotencyKey, "k2"},
+			want:   http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, replayed, body := post(tt.body, tt.header...)
+			if status != tt.want || replayed != "" {
+				t.Errorf("status %d, replayed %q: %s; want %d", status, replayed, body, tt.want)
+			}
+		})
+	}
+}
+
+// failingComplete — сервис ключей, который не может сохранить ответ.
+type failingComplete struct {
+	*service.IdempotencySvc
+}
+
+func (failingComplete) Complete(context.Context, string, model.IdempotentResponse) error {
+	return errors.New("storage is gone")
+}
+
+func TestIdempotentRelease(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newSvc := func() *service.IdempotencySvc {
+		cfg := &config.Config{Idempotency: config.Idempotency{TTL: time.Hour}}
+		return service.NewIdempotencyService(memory.New(), logger, cfg)
+	}
+	h := NewHandler(nil, logger)
+
+	tests := []struct {
+		name string
+		svc  IdempotencyService
+		// first — ответ первого запроса; повтор всегда отвечает 201.
+		first func(w http.ResponseWriter)
+	}{
+		{
+			name:  "5xx",
+			svc:   newSvc(),
+			first: func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+		},
+		{
+			name:  "panic",
+			svc:   newSvc(),
+			first: func(http.ResponseWriter) { panic("boom") },
+		},
+		{
+			name:  "response not saved",
+			svc:   failingComplete{newSvc()},
+			first: func(w http.ResponseWriter) { w.WriteHeader(http.StatusCreated) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					tt.first(w)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			})
+			router := h.Idempotent(tt.svc)(next)
+			header := []string{headerIdempotencyKey, "k1", "X-Actor", "alice"}
+
+			func() {
+				defer func() { _ = recover() }()
+				do(t, router, http.MethodPost, "/subscriptions", createBody, header...)
+			}()
+			rec := do(t, router, http.MethodPost, "/subscriptions", createBody, header...)
+			if rec.Code != http.StatusCreated || calls != 2 {
+				t.Errorf("retry: status %d after %d calls, want 201 after 2: %s", rec.Code, calls, rec.Body)
+			}
+		})
+	}
+}
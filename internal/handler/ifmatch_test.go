@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIfMatch(t *testing.T) {
+	const putBody = `{"service_name":"Netflix","price_minor":59900,"user_id":"` + testUserID + `","start_date":"01-2025"}`
+	tests := []struct {
+		name    string
+		method  string
+		ifMatch string // пусто — без заголовка
+		want    int
+	}{
+		{name: "no header", method: http.MethodPatch, want: http.StatusOK},
+		{name: "current version", method: http.MethodPatch, ifMatch: `"1"`, want: http.StatusOK},
+		{name: "stale version", method: http.MethodPatch, ifMatch: `"2"`, want: http.StatusPreconditionFailed},
+		{name: "weak tag never matches", method: http.MethodPatch, ifMatch: `W/"1"`, want: http.StatusPreconditionFailed},
+		{name: "list with current", method: http.MethodPatch, ifMatch: `"7", "1"`, want: http.StatusOK},
+		{name: "list of weak and stale", method: http.MethodPatch, ifMatch: `W/"1", "7"`, want: http.StatusPreconditionFailed},
+		{name: "wildcard", method: http.MethodPatch, ifMatch: `*`, want: http.StatusOK},
+		{name: "wildcard in list", method: http.MethodPatch, ifMatch: `"7", *`, want: http.StatusOK},
+		{name: "foreign strong tag", method: http.MethodPatch, ifMatch: `"abc"`, want: http.StatusPreconditionFailed},
+		{name: "unquoted", method: http.MethodPatch, ifMatch: `1`, want: http.StatusBadRequest},
+		{name: "put current", method: http.MethodPut, ifMatch: `"1"`, want: http.StatusOK},
+		{name: "put stale", method: http.MethodPut, ifMatch: `"2"`, want: http.StatusPreconditionFailed},
+		{name: "put weak", method: http.MethodPut, ifMatch: `W/"1"`, want: http.StatusPreconditionFailed},
+		{name: "delete current", method: http.MethodDelete, ifMatch: `"1"`, want: http.StatusNoContent},
+		{name: "delete stale", method: http.MethodDelete, ifMatch: `"2"`, want: http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t)
+			rec := do(t, router, http.MethodPost, "/subscriptions", createBody)
+			if rec.Code != http.StatusCreated || rec.Header().Get("ETag") != `"1"` {
+				t.Fatalf("create: status %d, ETag %q: %s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+			}
+
+			body := ""
+			switch tt.method {
+			case http.MethodPatch:
+				body = `{"price_minor":59900}`
+			case http.MethodPut:
+				body = putBody
+			}
+			var header []string
+			if tt.ifMatch != "" {
+				header = []string{"If-Match", tt.ifMatch}
+			}
+			rec = do(t, router, tt.method, "/subscriptions/1", body, header...)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+
+			// Успешное изменение поднимает версию, отклонённое ничего не меняет.
+			rec = do(t, router, http.MethodGet, "/subscriptions/1", "")
+			var sub struct {
+				Price int `json:"price_minor"`
+			}
+			switch tt.want {
+			case http.StatusOK:
+				decode(t, rec, &sub)
+				if etag := rec.Header().Get("ETag"); etag != `"2"` || sub.Price != 59900 {
+					t.Errorf("after update: ETag %q, price_minor %d; want \"2\", 59900", etag, sub.Price)
+				}
+			case http.StatusNoContent:
+				if rec.Code != http.StatusNotFound {
+					t.Errorf("after delete: status %d, want 404", rec.Code)
+				}
+			default:
+				decode(t, rec, &sub)
+				if etag := rec.Header().Get("ETag"); etag != `"1"` || sub.Price != 49900 {
+					t.Errorf("after rejected update: ETag %q, price_minor %d; want \"1\", 49900", etag, sub.Price)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"subscription/internal/model"
+)
+
+// errLegacyPrice — ответ на поле price. До перехода на минимальные единицы валюты оно было
+// в целых рублях; новое значение передаётся в price_minor, чтобы старый клиент получил 400,
+// а не цену в сто раз меньше.
+var errLegacyPrice = errors.New("price is replaced by price_minor: amounts are in minor currency units (14990 = 149.90)")
+
+// legacyPrice встраивается в тела запросов и ловит устаревшее поле price.
+type legacyPrice struct {
+	LegacyPrice json.RawMessage `json:"price"`
+}
+
+func (l legacyPrice) usesLegacyPrice() bool {
+	return l.LegacyPrice != nil
+}
+
+// introRequest — intro_price в теле POST и PUT /subscriptions.
+type introRequest struct {
+	model.IntroPrice
+	legacyPrice
+}
+
+// intro возвращает вводную цену или nil, если её нет в запросе.
+func (r *introRequest) intro() *model.IntroPrice {
+	if r == nil {
+		return nil
+	}
+	return &r.IntroPrice
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestLegacyPriceRejected проверяет, что поле price и параметры price_min/price_max, которые
+// до API 2.0 были в целых рублях, отклоняются везде, а не читаются как минимальные единицы.
+func TestLegacyPriceRejected(t *testing.T) {
+	router := newTestRouter(t)
+	rec := do(t, router, http.MethodPost, "/subscriptions", createBody)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+
+	legacySub := `{"service_name":"Netflix","price":499,"user_id":"` + testUserID + `","start_date":"01-2025"}`
+	legacyIntro := `{"service_name":"Spotify","price_minor":100,"user_id":"` + testUserID + `","start_date":"01-2025",` +
+		`"intro_price":{"price":10,"periods":1}}`
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		header []string
+	}{
+		{name: "create", method: http.MethodPost, target: "/subscriptions", body: legacySub},
+		{name: "create intro_price", method: http.MethodPost, target: "/subscriptions", body: legacyIntro},
+		{name: "update", method: http.MethodPut, target: "/subscriptions/1", body: legacySub},
+		{name: "update intro_price", method: http.MethodPut, target: "/subscriptions/1", body: legacyIntro},
+		{name: "patch", method: http.MethodPatch, target: "/subscriptions/1", body: `{"price":499}`},
+		{name: "patch intro_price", method: http.MethodPatch, target: "/subscriptions/1", body: `{"intro_price":{"price":10}}`},
+		{name: "price schedule", method: http.MethodPost, target: "/subscriptions/1/prices", body: `{"effective_from":"02-2025","price":599}`},
+		{name: "create plan", method: http.MethodPost, target: "/services/1/plans", body: `{"name":"basic","price":299}`},
+		{name: "update plan", method: http.MethodPut, target: "/services/1/plans/1", body: `{"name":"basic","price":299}`},
+		{name: "list price_min", method: http.MethodGet, target: "/subscriptions?price_min=100"},
+		{name: "list price_max", method: http.MethodGet, target: "/subscriptions?price_max=100"},
+		{name: "summary price_min", method: http.MethodGet, target: "/subscriptions/summary?from=01-2025&to=02-2025&price_min=100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, router, tt.method, tt.target, tt.body)
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "price_minor") {
+				t.Errorf("status %d: %s; want 400 pointing to price_minor", rec.Code, rec.Body)
+			}
+		})
+	}
+
+	// Подписка после отклонённых запросов не изменилась.
+	rec = do(t, router, http.MethodGet, "/subscriptions/1", "")
+	var sub struct {
+		Price int `json:"price_minor"`
+	}
+	decode(t, rec, &sub)
+	if sub.Price != 49900 {
+		t.Errorf("price_minor = %d, want 49900", sub.Price)
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"subscription/internal/model"
+	"time"
+)
+
+// parseFilter собирает фильтр подписок из query-параметров. Параметры со списком значений
+// принимают и повторы (service_name=a&service_name=b), и перечисление через запятую.
+func parseFilter(r *http.Request) (model.SubscriptionFilter, error) {
+	q := r.URL.Query()
+
+	var f model.SubscriptionFilter
+	f.UserIDs = splitValues(q, "user_id")
+	f.ServiceNames = splitValues(q, "service_name")
+
+	switch m := model.MatchMode(q.Get("service_name_match")); m {
+	case "", model.MatchExact:
+	case model.MatchIgnoreCase, model.MatchPrefix:
+		f.ServiceNameMatch = m
+	default:
+		return f, fmt.Errorf("invalid service_name_match: %q", m)
+	}
+
+	// Категории и метки хранятся в нижнем регистре.
+	for _, c := range splitValues(q, "category") {
+		f.Categories = append(f.Categories, strings.ToLower(c))
+	}
+	for _, tag := range splitValues(q, "tag") {
+		f.Tags = append(f.Tags, strings.ToLower(tag))
+	}
+
+	// price_min/price_max были в рублях; границы в минимальных единицах — price_minor_min/price_minor_max.
+	if q.Has("price_min") || q.Has("price_max") {
+		return f, fmt.Errorf("price_min and price_max are replaced by price_minor_min and price_minor_max (minor currency units)")
+	}
+	var err error
+	if f.PriceMin, err = parseIntParam(q, "price_minor_min"); err != nil {
+		return f, err
+	}
+	if f.PriceMax, err = parseIntParam(q, "price_minor_max"); err != nil {
+		return f, err
+	}
+	if f.PriceMin != nil && f.PriceMax != nil && *f.PriceMin > *f.PriceMax {
+		return f, fmt.Errorf("price_minor_min cannot be greater than price_minor_max")
+	}
+
+	activeAt, activeBetween := q.Get("active_at"), q.Get("active_between")
+	switch {
+	case activeAt != "" && activeBetween != "":
+		return f, fmt.Errorf("active_at and active_between are mutually exclusive")
+	case activeAt != "":
+		t, err := time.Parse("01-2006", activeAt)
+		if err != nil {
+			return f, fmt.Errorf("invalid active_at format")
+		}
+		f.ActiveWithin = &model.Period{From: t, To: t}
+	case activeBetween != "":
+		fromStr, toStr, ok := strings.Cut(activeBetween, ",")
+		if !ok {
+			return f, fmt.Errorf("invalid active_between format")
+		}
+		from, err := time.Parse("01-2006", strings.TrimSpace(fromStr))
+		if err != nil {
+			return f, fmt.Errorf("invalid active_between format")
+		}
+		to, err := time.Parse("01-2006", strings.TrimSpace(toStr))
+		if err != nil {
+			return f, fmt.Errorf("invalid active_between format")
+		}
+		if to.Before(from) {
+			return f, fmt.Errorf("active_between end cannot be before start")
+		}
+		f.ActiveWithin = &model.Period{From: from, To: to}
+	}
+
+	if f.HasEndDate, err = parseBoolParam(q, "has_end_date"); err != nil {
+		return f, err
+	}
+	if f.IncludeDeleted, err = parseIncludeDeleted(q); err != nil {
+		return f, err
+	}
+
+	return f, nil
+}
+
+// parseIncludeDeleted — флаг include_deleted: показывать ли мягко удалённые подписки.
+func parseIncludeDeleted(q url.Values) (bool, error) {
+	b, err := parseBoolParam(q, "include_deleted")
+	return b != nil && *b, err
+}
+
+// splitValues собирает все значения параметра с учётом перечисления через запятую.
+func splitValues(q url.Values, key string) []string {
+	var res []string
+	for _, v := range q[key] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				res = append(res, part)
+			}
+		}
+	}
+	return res
+}
+
+func parseIntParam(q url.Values, key string) (*int, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", key)
+	}
+	return &n, nil
+}
+
+func parseBoolParam(q url.Values, key string) (*bool, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", key)
+	}
+	return &b, nil
+}
+
+// parsePage собирает параметры страницы из limit, sort и cursor.
+// Лимит по умолчанию и верхнюю границу выставляет сервис.
+func parsePage(r *http.Request) (model.Page, error) {
+	q := r.URL.Query()
+
+	var page model.Page
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return page, fmt.Errorf("invalid limit")
+		}
+		page.Limit = limit
+	}
+
+	sort, desc, err := model.ParseSort(q.Get("sort"))
+	if err != nil {
+		return page, err
+	}
+	page.Sort, page.Desc = sort, desc
+
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := model.DecodeCursor(v, page)
+		if err != nil {
+			return page, fmt.Errorf("invalid cursor: %w", err)
+		}
+		page.After = cursor
+	}
+	return page, nil
+}
+
+// parseGroupBy проверяет измерения group_by; дубликаты игнорируются.
+func parseGroupBy(values []string) ([]model.GroupBy, error) {
+	var res []model.GroupBy
+	for _, v := range values {
+		g, ok := model.ParseGroupBy(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid group_by value: %q", v)
+		}
+		if !slices.Contains(res, g) {
+			res = append(res, g)
+		}
+	}
+	return res, nil
+}
+
+// parseInclude проверяет значения include; сейчас поддерживается только items.
+func parseInclude(values []string) (items bool, err error) {
+	for _, v := range values {
+		if v != "items" {
+			return false, fmt.Errorf("invalid include value: %q", v)
+		}
+		items = true
+	}
+	return items, nil
+}
+
+// parseIfMatch читает ожидаемые версии из If-Match: список ETag через запятую или «*».
+// Слабые ETag (W/"3") и сильные не нашего вида допустимы, но ни с чем не совпадают
+// (If-Match сравнивает только сильные), поэтому такой список даёт 412, а не 400.
+func parseIfMatch(r *http.Request) (model.IfMatch, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" {
+		return model.IfMatch{}, nil
+	}
+	match := model.IfMatch{Set: true}
+	for _, tag := range strings.Split(v, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return model.IfMatch{}, nil
+		}
+		opaque, weak := strings.CutPrefix(tag, "W/")
+		if len(opaque) < 2 || !strings.HasPrefix(opaque, `"`) || !strings.HasSuffix(opaque, `"`) {
+			return model.IfMatch{}, fmt.Errorf("invalid If-Match: %q", v)
+		}
+		if weak {
+			continue
+		}
+		if version, err := strconv.Atoi(opaque[1 : len(opaque)-1]); err == nil && version > 0 {
+			match.Versions = append(match.Versions, version)
+		}
+	}
+	return match, nil
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"subscription/internal/model"
+	"time"
+)
+
+// jsonNull — литерал null в теле merge patch.
+var jsonNull = []byte("null")
+
+// nullMemberError — null внутри billing_period, trial или intro_price. Все их поля обязательны,
+// и удаление поля по RFC 7396 оставило бы объект неполным, поэтому такой patch отклоняется:
+// вложенный объект целиком снимается null на верхнем уровне.
+type nullMemberError struct {
+	member string
+}
+
+func (e *nullMemberError) Error() string {
+	return e.member + " cannot be null"
+}
+
+// nullable — поля, которые можно очистить через null.
+var nullable = map[string]bool{"end_date": true, "trial": true, "intro_price": true, "plan_id": true, "category": true, "tags": true}
+
+// parseSubscriptionPatch разбирает тело PATCH как JSON Merge Patch (RFC 7396).
+// Отсутствующее поле не меняется, null очищает поле; очистить можно только end_date, trial,
+// intro_price, plan_id, category и tags, остальные поля обязательны. tags заменяются целиком. Неизвестные поля, в том числе id, — ошибка.
+// billing_period, trial и intro_price сливаются по полям, но null внутри них — ошибка (см. nullMemberError).
+func parseSubscriptionPatch(body io.Reader) (model.SubscriptionPatch, error) {
+	var patch model.SubscriptionPatch
+
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&fields); err != nil || fields == nil {
+		return patch, fmt.Errorf("body must be a JSON object")
+	}
+
+	for name, raw := range fields {
+		if !nullable[name] && bytes.Equal(raw, jsonNull) {
+			return patch, fmt.Errorf("%s cannot be null", name)
+		}
+
+		var err error
+		switch name {
+		case "service_name":
+			patch.ServiceName = new(string)
+			err = json.Unmarshal(raw, patch.ServiceName)
+		case "price":
+			return patch, errLegacyPrice
+		case "price_minor":
+			patch.Price = new(int)
+			err = json.Unmarshal(raw, patch.Price)
+		case "user_id":
+			patch.UserID = new(string)
+			err = json.Unmarshal(raw, patch.UserID)
+		case "start_date":
+			patch.StartDate, err = parseMonth(raw)
+		case "end_date":
+			if bytes.Equal(raw, jsonNull) {
+				patch.ClearEndDate = true
+				continue
+			}
+			patch.EndDate, err = parseMonth(raw)
+		case "currency":
+			patch.Currency = new(string)
+			err = json.Unmarshal(raw, patch.Currency)
+		case "billing_period":
+			err = parseBillingPeriodPatch(raw, &patch)
+		case "trial":
+			if bytes.Equal(raw, jsonNull) {
+				patch.ClearTrial = true
+				continue
+			}
+			err = parseTrialPatch(raw, &patch)
+		case "intro_price":
+			if bytes.Equal(raw, jsonNull) {
+				patch.ClearIntro = true
+				continue
+			}
+			err = parseIntroPatch(raw, &patch)
+		case "plan_id":
+			if bytes.Equal(raw, jsonNull) {
+				patch.ClearPlan = true
+				continue
+			}
+			patch.PlanID = new(int)
+			err = json.Unmarshal(raw, patch.PlanID)
+		case "category":
+			patch.Category = new(string)
+			if !bytes.Equal(raw, jsonNull) {
+				err = json.Unmarshal(raw, patch.Category)
+			}
+		case "tags":
+			patch.Tags = new([]string)
+			if !bytes.Equal(raw, jsonNull) {
+				err = json.Unmarshal(raw, patch.Tags)
+			}
+		default:
+			return patch, fmt.Errorf("unknown field: %q", name)
+		}
+		if errors.Is(err, errLegacyPrice) {
+			return patch, err
+		}
+		var nullErr *nullMemberError
+		if errors.As(err, &nullErr) {
+			return patch, fmt.Errorf("%s.%s", name, nullErr)
+		}
+		if err != nil {
+			return patch, fmt.Errorf("invalid %s", name)
+		}
+	}
+	return patch, nil
+}
+
+// parseBillingPeriodPatch сливает billing_period по полям: {"count": 2} меняет только count.
+func parseBillingPeriodPatch(raw json.RawMessage, patch *model.SubscriptionPatch) error {
+	var bp struct {
+		Unit  *model.BillingUnit `json:"unit"`
+		Count *int               `json:"count"`
+	}
+	if err := decodeObject(raw, &bp); err != nil {
+		return err
+	}
+	patch.BillingUnit, patch.BillingCount = bp.Unit, bp.Count
+	return nil
+}
+
+// parseTrialPatch сливает trial по полям, как billing_period.
+func parseTrialPatch(raw json.RawMessage, patch *model.SubscriptionPatch) error {
+	var t struct {
+		Unit  *model.TrialUnit `json:"unit"`
+		Count *int             `json:"count"`
+	}
+	if err := decodeObject(raw, &t); err != nil {
+		return err
+	}
+	patch.TrialUnit, patch.TrialCount = t.Unit, t.Count
+	return nil
+}
+
+// parseIntroPatch сливает intro_price по полям, как billing_period.
+func parseIntroPatch(raw json.RawMessage, patch *model.SubscriptionPatch) error {
+	var p struct {
+		Price   *int `json:"price_minor"`
+		Periods *int `json:"periods"`
+		legacyPrice
+	}
+	if err := decodeObject(raw, &p); err != nil {
+		return err
+	}
+	if p.usesLegacyPrice() {
+		return errLegacyPrice
+	}
+	patch.IntroPrice, patch.IntroPeriods = p.Price, p.Periods
+	return nil
+}
+
+// decodeObject разбирает вложенный объект patch, неизвестные поля и null в полях — ошибка.
+func decodeObject(raw json.RawMessage, v any) error {
+	var members map[string]json.RawMessage
+	if raw[0] != '{' || json.Unmarshal(raw, &members) != nil {
+		return fmt.Errorf("must be an object")
+	}
+	for name, value := range members {
+		if bytes.Equal(value, jsonNull) {
+			return &nullMemberError{member: name}
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if dec.Decode(v) != nil {
+		return fmt.Errorf("must be an object")
+	}
+	return nil
+}
+
+// parseMonth разбирает JSON-строку в формате MM-YYYY.
+func parseMonth(raw json.RawMessage) (*time.Time, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(model.MonthLayout, s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"subscription/internal/model"
+	"testing"
+	"time"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestParseSubscriptionPatch(t *testing.T) {
+	march := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		body    string
+		want    model.SubscriptionPatch
+		wantErr string
+	}{
+		{name: "empty object", body: `{}`},
+		{
+			name: "scalar fields",
+			body: `{"service_name": "Netflix", "price_minor": 14990, "end_date": "03-2025", "currency": "EUR"}`,
+			want: model.SubscriptionPatch{ServiceName: ptr("Netflix"), Price: ptr(14990), EndDate: &march, Currency: ptr("EUR")},
+		},
+		{name: "end_date null clears", body: `{"end_date": null}`, want: model.SubscriptionPatch{ClearEndDate: true}},
+		{name: "trial null clears", body: `{"trial": null}`, want: model.SubscriptionPatch{ClearTrial: true}},
+		{name: "intro_price null clears", body: `{"intro_price": null}`, want: model.SubscriptionPatch{ClearIntro: true}},
+		{name: "plan_id null clears", body: `{"plan_id": null}`, want: model.SubscriptionPatch{ClearPlan: true}},
+		{name: "category null clears", body: `{"category": null}`, want: model.SubscriptionPatch{Category: ptr("")}},
+		{name: "tags null clears", body: `{"tags": null}`, want: model.SubscriptionPatch{Tags: new([]string)}},
+		{name: "tags replaced", body: `{"tags": ["video"]}`, want: model.SubscriptionPatch{Tags: &[]string{"video"}}},
+		{
+			name: "billing_period merged by member",
+			body: `{"billing_period": {"count": 2}}`,
+			want: model.SubscriptionPatch{BillingCount: ptr(2)},
+		},
+		{
+			name: "trial merged by member",
+			body: `{"trial": {"count": 14}}`,
+			want: model.SubscriptionPatch{TrialCount: ptr(14)},
+		},
+		{
+			name: "intro_price merged by member",
+			body: `{"intro_price": {"price_minor": 100, "periods": 3}}`,
+			want: model.SubscriptionPatch{IntroPrice: ptr(100), IntroPeriods: ptr(3)},
+		},
+		{name: "service_name cannot be null", body: `{"service_name": null}`, wantErr: "service_name cannot be null"},
+		{name: "price_minor cannot be null", body: `{"price_minor": null}`, wantErr: "price_minor cannot be null"},
+		{name: "billing_period cannot be null", body: `{"billing_period": null}`, wantErr: "billing_period cannot be null"},
+		{name: "null inside billing_period", body: `{"billing_period": {"unit": null}}`, wantErr: "billing_period.unit cannot be null"},
+		{name: "null inside trial", body: `{"trial": {"count": null}}`, wantErr: "trial.count cannot be null"},
+		{name: "null inside intro_price", body: `{"intro_price": {"periods": null}}`, wantErr: "intro_price.periods cannot be null"},
+		{name: "unknown field", body: `{"colour": "red"}`, wantErr: `unknown field: "colour"`},
+		{name: "id is not patchable", body: `{"id": 2}`, wantErr: `unknown field: "id"`},
+		{name: "unknown member of trial", body: `{"trial": {"days": 14}}`, wantErr: "invalid trial"},
+		{name: "trial is not an object", body: `{"trial": 14}`, wantErr: "invalid trial"},
+		{name: "legacy price", body: `{"price": 149.9}`, wantErr: errLegacyPrice.Error()},
+		{name: "legacy price in intro_price", body: `{"intro_price": {"price": 1}}`, wantErr: errLegacyPrice.Error()},
+		{name: "wrong type", body: `{"price_minor": "100"}`, wantErr: "invalid price_minor"},
+		{name: "bad month", body: `{"start_date": "2025-03"}`, wantErr: "invalid start_date"},
+		{name: "not an object", body: `[]`, wantErr: "body must be a JSON object"},
+		{name: "null body", body: `null`, wantErr: "body must be a JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSubscriptionPatch(strings.NewReader(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("patch = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
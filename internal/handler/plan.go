@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"subscription/internal/model"
+)
+
+// planRequest — тело POST и PUT /services/{id}/plans.
+type planRequest struct {
+	Name     string `json:"name"`
+	Price    int    `json:"price_minor"`
+	Currency string `json:"currency"`
+
+	legacyPrice
+}
+
+// ListPlans отдаёт тарифы сервиса.
+func (h *Handler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	serviceID, ok := h.serviceID(w, r)
+	if !ok {
+		return
+	}
+	plans, err := h.services.ListPlans(r.Context(), serviceID)
+	if err != nil {
+		h.log.Error("list plans error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, plans)
+}
+
+func (h *Handler) GetPlan(w http.ResponseWriter, r *http.Request) {
+	serviceID, planID, ok := h.planID(w, r)
+	if !ok {
+		return
+	}
+	plan, err := h.services.GetPlan(r.Context(), serviceID, planID)
+	if err != nil {
+		h.log.Error("get plan error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, plan)
+}
+
+func (h *Handler) CreatePlan(w http.ResponseWriter, r *http.Request) {
+	serviceID, ok := h.serviceID(w, r)
+	if !ok {
+		return
+	}
+	var req planRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("invalid request", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.usesLegacyPrice() {
+		h.writeError(w, http.StatusBadRequest, errLegacyPrice.Error())
+		return
+	}
+	plan, err := h.services.CreatePlan(r.Context(), model.Plan{
+		ServiceID: serviceID,
+		Name:      req.Name,
+		Price:     req.Price,
+		Currency:  req.Currency,
+	})
+	if err != nil {
+		h.log.Error("create plan error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, plan)
+}
+
+func (h *Handler) UpdatePlan(w http.ResponseWriter, r *http.Request) {
+	serviceID, planID, ok := h.planID(w, r)
+	if !ok {
+		return
+	}
+	var req planRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("invalid request", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.usesLegacyPrice() {
+		h.writeError(w, http.StatusBadRequest, errLegacyPrice.Error())
+		return
+	}
+	plan, err := h.services.UpdatePlan(r.Context(), model.Plan{
+		ID:        planID,
+		ServiceID: serviceID,
+		Name:      req.Name,
+		Price:     req.Price,
+		Currency:  req.Currency,
+	})
+	if err != nil {
+		h.log.Error("update plan error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, plan)
+}
+
+func (h *Handler) DeletePlan(w http.ResponseWriter, r *http.Request) {
+	serviceID, planID, ok := h.planID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.services.DeletePlan(r.Context(), serviceID, planID); err != nil {
+		h.log.Error("delete plan error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// planID читает из пути id сервиса и тарифа.
+func (h *Handler) planID(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	serviceID, ok := h.serviceID(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+	planID, err := strconv.Atoi(chi.URLParam(r, "plan_id"))
+	if err != nil {
+		h.log.Error("invalid plan_id", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid plan_id")
+		return 0, 0, false
+	}
+	return serviceID, planID, true
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"subscription/internal/model"
+	"time"
+)
+
+// SchedulePriceChange планирует новую цену подписки с указанного месяца.
+func (h *Handler) SchedulePriceChange(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.log.Error("invalid id", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req struct {
+		EffectiveFrom string `json:"effective_from"` // "MM-YYYY"
+		Price         int    `json:"price_minor"`
+
+		legacyPrice
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("invalid request", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.usesLegacyPrice() {
+		h.writeError(w, http.StatusBadRequest, errLegacyPrice.Error())
+		return
+	}
+
+	ch := model.PriceChange{SubscriptionID: id, Price: req.Price}
+	if req.EffectiveFrom != "" {
+		if ch.EffectiveFrom, err = time.Parse(model.MonthLayout, req.EffectiveFrom); err != nil {
+			h.log.Error("invalid effective_from", "value", req.EffectiveFrom, "err", err)
+			h.writeError(w, http.StatusBadRequest, "invalid effective_from format")
+			return
+		}
+	}
+
+	ch, err = h.services.SchedulePriceChange(r.Context(), ch)
+	if err != nil {
+		h.log.Error("schedule price error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, ch)
+}
+
+// PriceSchedule отдаёт график цен подписки.
+func (h *Handler) PriceSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.log.Error("invalid id", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	prices, err := h.services.PriceSchedule(r.Context(), id)
+	if err != nil {
+		h.log.Error("price schedule error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, prices)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+	"subscription/internal/model"
+	"time"
+)
+
+// defaultRenewalMonths — сколько месяцев, считая from, охватывает календарь без ?to=.
+const defaultRenewalMonths = 12
+
+// Renewals отдаёт календарь списаний пользователя за месяцы ?from=MM-YYYY..?to=MM-YYYY.
+// По умолчанию from — текущий месяц, to — через defaultRenewalMonths-1 месяцев после него.
+func (h *Handler) Renewals(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(model.MonthLayout, v)
+		if err != nil {
+			h.log.Error("invalid from", "value", v, "err", err)
+			h.writeError(w, http.StatusBadRequest, "invalid from format")
+			return
+		}
+		from = t
+	}
+	to := from.AddDate(0, defaultRenewalMonths-1, 0)
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(model.MonthLayout, v)
+		if err != nil {
+			h.log.Error("invalid to", "value", v, "err", err)
+			h.writeError(w, http.StatusBadRequest, "invalid to format")
+			return
+		}
+		to = t
+	}
+	if to.Before(from) {
+		h.writeError(w, http.StatusBadRequest, "to cannot be before from")
+		return
+	}
+
+	calendar, err := h.services.Renewals(r.Context(), chi.URLParam(r, "user_id"), from, to, strings.ToUpper(q.Get("currency")))
+	if err != nil {
+		h.log.Error("renewals error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, calendar)
+}
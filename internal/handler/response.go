@@ -2,16 +2,45 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"subscription/internal/service"
 )
 
 const (
 	StatusError = "Error"
 )
 
+// Машиночитаемые коды ошибок в поле code.
+const (
+	CodeInvalidRequest = "invalid_request"
+	CodeValidation     = "validation_failed"
+	CodeNotFound       = "not_found"
+	CodeConflict       = "conflict"
+	CodeOverlap        = "subscription_overlap"
+	CodePrecondition   = "precondition_failed"
+	CodeKeyReused      = "idempotency_key_reused"
+	CodeUnavailable    = "unavailable"
+	CodeInternal       = "internal_error"
+)
+
 type apiError struct {
-	Status string `json:"status"`
-	Error  string `json:"error"`
+	Status  string `json:"status"`
+	Code    string `json:"code"`
+	Error   string `json:"error"`
+	Details any    `json:"details,omitempty"`
+}
+
+// validationDetails — подробности ошибки 400 при проверке полей.
+type validationDetails struct {
+	Fields map[string]string `json:"fields"`
+}
+
+// conflictDetails — подробности ошибки 409 при пересечении подписок.
+type conflictDetails struct {
+	SubscriptionID int `json:"subscription_id,omitempty"`
+	ConflictingID  int `json:"conflicting_subscription_id,omitempty"`
 }
 
 func (h *Handler) writeJSON(w http.ResponseWriter, status int, v any) {
@@ -30,6 +59,54 @@ func (h *Handler) writeJSON(w http.ResponseWriter, status int, v any) {
 	}
 }
 
+// setETag отдаёт версию подписки как сильный ETag; клиент возвращает его в If-Match.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// writeError — ошибка разбора запроса в самом хендлере; code выводится из статуса.
 func (h *Handler) writeError(w http.ResponseWriter, status int, msg string) {
-	h.writeJSON(w, status, apiError{Status: StatusError, Error: msg})
+	code := CodeInvalidRequest
+	switch status {
+	case http.StatusNotFound:
+		code = CodeNotFound
+	case http.StatusInternalServerError:
+		code = CodeInternal
+	}
+	h.writeJSON(w, status, apiError{Status: StatusError, Code: code, Error: msg})
+}
+
+// writeServiceError — единственное место, где ошибки сервиса и хранилищ превращаются в HTTP-статусы.
+// Неизвестные ошибки отдаются как 500 без подробностей: текст может содержать детали БД.
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error) {
+	resp := apiError{Status: StatusError}
+	status := http.StatusInternalServerError
+
+	var validation *service.ValidationError
+	var overlap *service.OverlapError
+	switch {
+	case errors.As(err, &validation):
+		status, resp.Code, resp.Error = http.StatusBadRequest, CodeValidation, "validation failed"
+		resp.Details = validationDetails{Fields: validation.Fields}
+	case errors.Is(err, service.ErrNotFound):
+		status, resp.Code, resp.Error = http.StatusNotFound, CodeNotFound, "not found"
+	case errors.As(err, &overlap):
+		status, resp.Code, resp.Error = http.StatusConflict, CodeOverlap, overlap.Error()
+		resp.Details = conflictDetails{SubscriptionID: overlap.SubscriptionID, ConflictingID: overlap.ConflictingID}
+	case errors.Is(err, service.ErrPreconditionFailed):
+		status, resp.Code, resp.Error = http.StatusPreconditionFailed, CodePrecondition, "subscription was modified, re-read it and retry"
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		status, resp.Code, resp.Error = http.StatusUnprocessableEntity, CodeKeyReused, err.Error()
+	case errors.Is(err, service.ErrDuplicate):
+		// Текст ошибки БД клиенту не отдаём.
+		status, resp.Code, resp.Error = http.StatusConflict, CodeConflict, service.ErrDuplicate.Error()
+	case errors.Is(err, service.ErrConflict):
+		status, resp.Code, resp.Error = http.StatusConflict, CodeConflict, err.Error()
+	case errors.Is(err, service.ErrUnavailable):
+		status, resp.Code, resp.Error = http.StatusServiceUnavailable, CodeUnavailable, "service temporarily unavailable"
+	default:
+		resp.Code, resp.Error = CodeInternal, "server error"
+	}
+
+	h.writeJSON(w, status, resp)
 }
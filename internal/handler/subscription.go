@@ -2,13 +2,14 @@ package handler
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"subscription/internal/model"
 	"subscription/internal/service"
 
@@ -19,11 +20,38 @@ import (
 // Его будет реализовывать service.SubscriptionSvc.
 type SubscriptionService interface {
 	CreateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error)
-	GetSubscription(ctx context.Context, id int) (model.Subscription, error)
-	ListSubscriptions(ctx context.Context, userID, serviceName string) ([]*model.Subscription, error)
-	UpdateSubscription(ctx context.Context, sub model.Subscription) error
-	DeleteSubscription(ctx context.Context, id int) error
-	Sum(ctx context.Context, userID, serviceName string, startPeriod, endPeriod time.Time) (int, error)
+	GetSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error)
+	ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Page) (model.SubscriptionList, error)
+	UpdateSubscription(ctx context.Context, sub model.Subscription, ifMatch model.IfMatch) (model.Subscription, error)
+	PatchSubscription(ctx context.Context, id int, ifMatch model.IfMatch, patch model.SubscriptionPatch) (model.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int, ifMatch model.IfMatch) error
+	RestoreSubscription(ctx context.Context, id int) (model.Subscription, error)
+	SubscriptionHistory(ctx context.Context, id int) (model.SubscriptionHistory, error)
+	SchedulePriceChange(ctx context.Context, ch model.PriceChange) (model.PriceChange, error)
+	PriceSchedule(ctx context.Context, id int) (model.PriceChangeList, error)
+	ListFXRates(ctx context.Context) (model.FXRateList, error)
+	SetFXRate(ctx context.Context, rate model.FXRate) (model.FXRate, error)
+	DeleteFXRate(ctx context.Context, base, quote string, validFrom time.Time) error
+	ImportFXRates(ctx context.Context, r io.Reader) (int, error)
+	ListServices(ctx context.Context) (model.ServiceList, error)
+	GetService(ctx context.Context, id int) (model.Service, error)
+	CreateService(ctx context.Context, svc model.Service) (model.Service, error)
+	UpdateService(ctx context.Context, svc model.Service) (model.Service, error)
+	DeleteService(ctx context.Context, id int) error
+	ListPlans(ctx context.Context, serviceID int) (model.PlanList, error)
+	GetPlan(ctx context.Context, serviceID, planID int) (model.Plan, error)
+	CreatePlan(ctx context.Context, plan model.Plan) (model.Plan, error)
+	UpdatePlan(ctx context.Context, plan model.Plan) (model.Plan, error)
+	DeletePlan(ctx context.Context, serviceID, planID int) error
+	ListBudgets(ctx context.Context, userID string) (model.BudgetList, error)
+	GetBudget(ctx context.Context, id int) (model.Budget, error)
+	CreateBudget(ctx context.Context, b model.Budget) (model.Budget, error)
+	UpdateBudget(ctx context.Context, b model.Budget) (model.Budget, error)
+	DeleteBudget(ctx context.Context, id int) error
+	BudgetStatus(ctx context.Context, id int, month time.Time) (model.BudgetStatus, error)
+	BudgetAlerts(ctx context.Context, id int) (model.BudgetAlertList, error)
+	Renewals(ctx context.Context, userID string, from, to time.Time, currency string) (model.RenewalCalendar, error)
+	Sum(ctx context.Context, filter model.SubscriptionFilter, startPeriod, endPeriod time.Time, opts model.SummaryOptions) (model.Summary, error)
 	Ping(ctx context.Context) error
 }
 
@@ -37,61 +65,100 @@ func NewHandler(services SubscriptionService, log *slog.Logger) *Handler {
 }
 
 func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		ServiceName string `json:"service_name"`
-		Price       int    `json:"price"`
-		UserID      string `json:"user_id"`
-		StartDate   string `json:"start_date"` // "MM-YYYY"
-		EndDate     string `json:"end_date,omitempty"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.log.Error("invalid request", "err", err)
-		h.writeError(w, http.StatusBadRequest, "invalid request")
+	s, ok := h.decodeSubscription(w, r)
+	if !ok {
 		return
 	}
-
-	startDate, err := time.Parse("01-2006", req.StartDate)
+	s, err := h.services.CreateSubscription(r.Context(), s)
 	if err != nil {
-		h.log.Error("invalid start_date", "value", req.StartDate, "err", err)
-		h.writeError(w, http.StatusBadRequest, "invalid start_date format")
+		h.log.Error("create subscription error", "err", err)
+		h.writeServiceError(w, err)
 		return
 	}
 
+	setETag(w, s.Version)
+	h.writeJSON(w, http.StatusCreated, s)
+
+}
+
+// subscriptionRequest — тело POST /subscriptions и PUT /subscriptions/{id}.
+type subscriptionRequest struct {
+	ServiceName string `json:"service_name"`
+	Price       *int   `json:"price_minor"`
+	UserID      string `json:"user_id"`
+	StartDate   string `json:"start_date"` // "MM-YYYY"
+	EndDate     string `json:"end_date,omitempty"`
+
+	BillingPeriod model.BillingPeriod `json:"billing_period"`
+	Currency      string              `json:"currency"`
+	Trial         *model.Trial        `json:"trial"`
+	Intro         *introRequest       `json:"intro_price"`
+	PlanID        *int                `json:"plan_id"`
+	Category      string              `json:"category"`
+	Tags          []string            `json:"tags"`
+
+	legacyPrice
+}
+
+// subscription проверяет даты и собирает подписку; текст ошибки уходит клиенту с 400.
+// Без price_minor цена 0, как и раньше, а у подписки с тарифом её подставит сервис
+// (model.Subscription.PriceFromPlan).
+func (req subscriptionRequest) subscription() (model.Subscription, error) {
+	if req.usesLegacyPrice() || req.Intro != nil && req.Intro.usesLegacyPrice() {
+		return model.Subscription{}, errLegacyPrice
+	}
+	startDate, err := time.Parse(model.MonthLayout, req.StartDate)
+	if err != nil {
+		return model.Subscription{}, fmt.Errorf("invalid start_date format")
+	}
 	var endDate *time.Time
 	if req.EndDate != "" {
-		t, err := time.Parse("01-2006", req.EndDate)
+		t, err := time.Parse(model.MonthLayout, req.EndDate)
 		if err != nil {
-			h.log.Error("invalid end_date", "value", req.EndDate, "err", err)
-			h.writeError(w, http.StatusBadRequest, "invalid end_date format")
-			return
+			return model.Subscription{}, fmt.Errorf("invalid end_date format")
 		}
-
-		// Проверка: end_date >= start_date
 		if t.Before(startDate) {
-			h.log.Error("end_date before start_date", "start_date", startDate, "end_date", t)
-			h.writeError(w, http.StatusBadRequest, "end_date cannot be before start_date")
-			return
+			return model.Subscription{}, fmt.Errorf("end_date cannot be before start_date")
 		}
 		endDate = &t
 	}
-
-	s := model.Subscription{
+	var price int
+	if req.Price != nil {
+		price = *req.Price
+	}
+	return model.Subscription{
 		ServiceName: req.ServiceName,
-		Price:       req.Price,
+		Price:       price,
 		UserID:      req.UserID,
 		StartDate:   startDate,
 		EndDate:     endDate,
+
+		BillingPeriod: req.BillingPeriod,
+		Currency:      req.Currency,
+		Trial:         req.Trial,
+		Intro:         req.Intro.intro(),
+		PlanID:        req.PlanID,
+		Category:      req.Category,
+		Tags:          req.Tags,
+		PriceFromPlan: req.Price == nil && req.PlanID != nil,
+	}, nil
+}
+
+// decodeSubscription разбирает subscriptionRequest; при ошибке отвечает 400 и возвращает false.
+func (h *Handler) decodeSubscription(w http.ResponseWriter, r *http.Request) (model.Subscription, bool) {
+	var req subscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("invalid request", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid request")
+		return model.Subscription{}, false
 	}
-	s, err = h.services.CreateSubscription(r.Context(), s)
+	sub, err := req.subscription()
 	if err != nil {
-		h.log.Error("create subscription error", "err", err)
-		h.writeError(w, http.StatusInternalServerError, "could not create subscription")
-		return
+		h.log.Error("invalid subscription", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return model.Subscription{}, false
 	}
-
-	h.writeJSON(w, http.StatusCreated, s)
-
+	return sub, true
 }
 
 func (h *Handler) GetSubscription(w http.ResponseWriter, r *http.Request) {
@@ -103,34 +170,48 @@ func (h *Handler) GetSubscription(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sub, err := h.services.GetSubscription(r.Context(), id)
+	includeDeleted, err := parseIncludeDeleted(r.URL.Query())
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			h.writeError(w, http.StatusNotFound, "not found")
-		} else {
-			h.log.Error("get error", "err", err)
-			h.writeError(w, http.StatusInternalServerError, "server error")
-		}
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sub, err := h.services.GetSubscription(r.Context(), id, includeDeleted)
+	if err != nil {
+		h.log.Error("get error", "err", err)
+		h.writeServiceError(w, err)
 		return
 	}
 
+	setETag(w, sub.Version)
 	h.writeJSON(w, http.StatusOK, sub)
 
 }
 
 func (h *Handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
 
-	userID := r.URL.Query().Get("user_id")
-	serviceName := r.URL.Query().Get("service_name")
+	filter, err := parseFilter(r)
+	if err != nil {
+		h.log.Error("invalid filter", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := parsePage(r)
+	if err != nil {
+		h.log.Error("invalid page params", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	subs, err := h.services.ListSubscriptions(r.Context(), userID, serviceName)
+	list, err := h.services.ListSubscriptions(r.Context(), filter, page)
 	if err != nil {
 		h.log.Error("list error", "err", err)
-		h.writeError(w, http.StatusInternalServerError, "server error")
+		h.writeServiceError(w, err)
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, subs)
+	h.writeJSON(w, http.StatusOK, list)
 
 }
 
@@ -142,53 +223,57 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, http.StatusBadRequest, "invalid id")
 		return
 	}
-	var req struct {
-		ServiceName string `json:"service_name"`
-		Price       int    `json:"price"`
-		UserID      string `json:"user_id"`
-		StartDate   string `json:"start_date"`
-		EndDate     string `json:"end_date,omitempty"`
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		h.log.Error("invalid If-Match", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.log.Error("invalid request", "err", err)
-		h.writeError(w, http.StatusBadRequest, "invalid request")
+	sub, ok := h.decodeSubscription(w, r)
+	if !ok {
 		return
 	}
-	startDate, err := time.Parse("01-2006", req.StartDate)
+	sub.ID = id
+	updated, err := h.services.UpdateSubscription(r.Context(), sub, ifMatch)
 	if err != nil {
-		h.log.Error("invalid start_date", "value", req.StartDate, "err", err)
-		h.writeError(w, http.StatusBadRequest, "invalid start_date format")
+		h.log.Error("update error", "err", err)
+		h.writeServiceError(w, err)
 		return
 	}
-	var endDate *time.Time
-	if req.EndDate != "" {
-		t, err := time.Parse("01-2006", req.EndDate)
-		if err != nil {
-			h.log.Error("invalid end_date", "value", req.EndDate, "err", err)
-			h.writeError(w, http.StatusBadRequest, "invalid end_date format")
-			return
-		}
-		if t.Before(startDate) {
-			h.log.Error("end_date before start_date", "start_date", startDate, "end_date", t)
-			h.writeError(w, http.StatusBadRequest, "end_date cannot be before start_date")
-			return
-		}
-		endDate = &t
+	setETag(w, updated.Version)
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// PatchSubscription меняет только переданные поля (JSON Merge Patch), в отличие от PUT,
+// который перезаписывает подписку целиком.
+func (h *Handler) PatchSubscription(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Error("invalid id", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid id")
+		return
 	}
-	sub := model.Subscription{
-		ID:          id,
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      req.UserID,
-		StartDate:   startDate,
-		EndDate:     endDate,
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		h.log.Error("invalid If-Match", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	if err := h.services.UpdateSubscription(r.Context(), sub); err != nil {
-		h.log.Error("update error", "err", err)
-		h.writeError(w, http.StatusInternalServerError, "server error")
+	patch, err := parseSubscriptionPatch(r.Body)
+	if err != nil {
+		h.log.Error("invalid patch", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated, err := h.services.PatchSubscription(r.Context(), id, ifMatch, patch)
+	if err != nil {
+		h.log.Error("patch error", "err", err)
+		h.writeServiceError(w, err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	setETag(w, updated.Version)
+	h.writeJSON(w, http.StatusOK, updated)
 }
 
 func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
@@ -199,17 +284,72 @@ func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, http.StatusBadRequest, "invalid id")
 		return
 	}
-	if err := h.services.DeleteSubscription(r.Context(), id); err != nil {
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		h.log.Error("invalid If-Match", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.services.DeleteSubscription(r.Context(), id, ifMatch); err != nil {
 		h.log.Error("delete error", "err", err)
-		h.writeError(w, http.StatusInternalServerError, "server error")
+		h.writeServiceError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreSubscription возвращает мягко удалённую подписку.
+func (h *Handler) RestoreSubscription(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Error("invalid id", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	sub, err := h.services.RestoreSubscription(r.Context(), id)
+	if err != nil {
+		h.log.Error("restore error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	setETag(w, sub.Version)
+	h.writeJSON(w, http.StatusOK, sub)
+}
+
+// SubscriptionHistory отдаёт журнал изменений подписки.
+func (h *Handler) SubscriptionHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Error("invalid id", "err", err)
+		h.writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	history, err := h.services.SubscriptionHistory(r.Context(), id)
+	if err != nil {
+		h.log.Error("history error", "err", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, history)
+}
+
+// SumSubscriptions отдаёт стоимость подписок за месяцы ?from..?to. Период суммы задают только
+// они, поэтому active_at и active_between общего фильтра здесь отклоняются.
 func (h *Handler) SumSubscriptions(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	serviceName := r.URL.Query().Get("service_name")
+	for _, name := range []string{"active_at", "active_between"} {
+		if r.URL.Query().Has(name) {
+			h.writeError(w, http.StatusBadRequest, name+" is not supported by summary, use from and to")
+			return
+		}
+	}
+	filter, err := parseFilter(r)
+	if err != nil {
+		h.log.Error("invalid filter", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	fromStr := r.URL.Query().Get("from")
 	toStr := r.URL.Query().Get("to")
 	if fromStr == "" || toStr == "" {
@@ -228,18 +368,46 @@ func (h *Handler) SumSubscriptions(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, http.StatusBadRequest, "invalid to format")
 		return
 	}
+	if endPeriod.Before(startPeriod) {
+		h.writeError(w, http.StatusBadRequest, "to cannot be before from")
+		return
+	}
+	if model.MonthsBetween(startPeriod, endPeriod) > model.MaxSummaryMonths {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("period cannot be longer than %d months", model.MaxSummaryMonths))
+		return
+	}
+	groupBy, err := parseGroupBy(splitValues(r.URL.Query(), "group_by"))
+	if err != nil {
+		h.log.Error("invalid group_by", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	mode, ok := model.ParseSumMode(r.URL.Query().Get("mode"))
+	if !ok {
+		h.writeError(w, http.StatusBadRequest, "invalid mode")
+		return
+	}
+	includeItems, err := parseInclude(splitValues(r.URL.Query(), "include"))
+	if err != nil {
+		h.log.Error("invalid include", "err", err)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	sum, err := h.services.Sum(r.Context(), userID, serviceName, startPeriod, endPeriod)
+	opts := model.SummaryOptions{
+		GroupBy:      groupBy,
+		Mode:         mode,
+		Currency:     strings.ToUpper(r.URL.Query().Get("currency")),
+		IncludeItems: includeItems,
+	}
+	summary, err := h.services.Sum(r.Context(), filter, startPeriod, endPeriod, opts)
 	if err != nil {
 		h.log.Error("sum error", "err", err)
-		h.writeError(w, http.StatusInternalServerError, "server error")
+		h.writeServiceError(w, err)
 		return
 	}
-	resp := struct {
-		Total int `json:"total"`
-	}{Total: sum}
 
-	h.writeJSON(w, http.StatusOK, resp)
+	h.writeJSON(w, http.StatusOK, summary)
 
 }
 
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"subscription/internal/config"
+	"subscription/internal/middleware/audit"
+	"subscription/internal/repository/memory"
+	"subscription/internal/service"
+	"testing"
+	"time"
+)
+
+const testUserID = "123e4567-e89b-12d3-a456-426614174000"
+
+// newTestRouter собирает маршруты подписок, как cmd/main.go, поверх хранилища в памяти.
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := memory.New()
+	cfg := &config.Config{Idempotency: config.Idempotency{TTL: time.Hour}}
+	h := NewHandler(service.NewSubscriptionService(repo, logger, cfg), logger)
+
+	r := chi.NewRouter()
+	r.Use(audit.New())
+	r.With(h.Idempotent(service.NewIdempotencyService(repo, logger, cfg))).Post("/subscriptions", h.CreateSubscription)
+	r.Get("/subscriptions", h.ListSubscriptions)
+	r.Get("/subscriptions/{id}", h.GetSubscription)
+	r.Put("/subscriptions/{id}", h.UpdateSubscription)
+	r.Patch("/subscriptions/{id}", h.PatchSubscription)
+	r.Delete("/subscriptions/{id}", h.DeleteSubscription)
+	r.Post("/subscriptions/{id}/prices", h.SchedulePriceChange)
+	r.Get("/subscriptions/summary", h.SumSubscriptions)
+	r.Post("/services/{id}/plans", h.CreatePlan)
+	r.Put("/services/{id}/plans/{plan_id}", h.UpdatePlan)
+	return r
+}
+
+// do выполняет запрос к router; header — пары имя, значение.
+func do(t *testing.T, router http.Handler, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode разбирает JSON-ответ в v.
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+}
+
+func TestSumSubscriptionsPeriod(t *testing.T) {
+	router := newTestRouter(t)
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "ten years", query: "from=01-2015&to=12-2024", want: http.StatusOK},
+		{name: "longer than ten years", query: "from=01-2015&to=01-2025", want: http.StatusBadRequest},
+		{name: "whole calendar by month", query: "from=01-0001&to=12-9999&group_by=month", want: http.StatusBadRequest},
+		{name: "to before from", query: "from=02-2025&to=01-2025", want: http.StatusBadRequest},
+		{name: "active_at", query: "from=01-2025&to=02-2025&active_at=01-2025", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, router, http.MethodGet, "/subscriptions/summary?"+tt.query, "")
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"net/http"
+	"strings"
+	"subscription/internal/service"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ActorHeader — заголовок, которым клиент сообщает, кто выполняет изменение.
+const ActorHeader = "X-Actor"
+
+// New кладёт в контекст запроса данные для журнала изменений: actor из X-Actor
+// и request id, выданный middleware.RequestID (должен стоять раньше в цепочке).
+func New() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := service.WithAuditInfo(r.Context(), service.AuditInfo{
+				Actor:     strings.TrimSpace(r.Header.Get(ActorHeader)),
+				RequestID: middleware.GetReqID(r.Context()),
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// BillingUnit — единица периода списания.
+type BillingUnit string
+
+const (
+	BillingWeek    BillingUnit = "week"
+	BillingMonth   BillingUnit = "month"
+	BillingQuarter BillingUnit = "quarter"
+	BillingYear    BillingUnit = "year"
+)
+
+// maxBillingCount — наибольшее число единиц в периоде: период списания не длиннее 10 лет.
+// Заодно это держит даты в Add далеко от переполнения.
+var maxBillingCount = map[BillingUnit]int{
+	BillingWeek:    520,
+	BillingMonth:   120,
+	BillingQuarter: 40,
+	BillingYear:    10,
+}
+
+// weeksPerMonth — среднее число недель в месяце (365.25 / 7 / 12).
+const weeksPerMonth = 365.25 / 7 / 12
+
+// BillingPeriod — как часто списывается Subscription.Price: раз в Count единиц Unit.
+// Нулевое значение означает «раз в месяц».
+type BillingPeriod struct {
+	Unit  BillingUnit `json:"unit"`
+	Count int         `json:"count"`
+}
+
+// MonthlyBilling — период по умолчанию: цена за месяц.
+var MonthlyBilling = BillingPeriod{Unit: BillingMonth, Count: 1}
+
+// OrDefault подставляет период по умолчанию вместо незаполненных полей.
+func (p BillingPeriod) OrDefault() BillingPeriod {
+	if p.Unit == "" {
+		p.Unit = MonthlyBilling.Unit
+	}
+	if p.Count == 0 {
+		p.Count = MonthlyBilling.Count
+	}
+	return p
+}
+
+// Validate проверяет единицу и число единиц в периоде (от 1 до maxBillingCount).
+func (p BillingPeriod) Validate() error {
+	limit, ok := maxBillingCount[p.Unit]
+	if !ok {
+		return fmt.Errorf("unit must be one of week, month, quarter, year")
+	}
+	if p.Count < 1 {
+		return fmt.Errorf("count must be positive")
+	}
+	if p.Count > limit {
+		return fmt.Errorf("count must be at most %d for %s (10 years)", limit, p.Unit)
+	}
+	return nil
+}
+
+// MonthlyShare — доля цены за период, приходящаяся на один месяц.
+func (p BillingPeriod) MonthlyShare() float64 {
+	n := float64(p.Count)
+	switch p.Unit {
+	case BillingWeek:
+		return weeksPerMonth / n
+	case BillingQuarter:
+		return 1 / (3 * n)
+	case BillingYear:
+		return 1 / (12 * n)
+	}
+	return 1 / n
+}
+
+// Add — дата через n периодов после t. n-е списание считается от первого, а не от предыдущего,
+// а день, которого нет в месяце, сдвигается на последний день месяца: с 31 января списания идут
+// 28 февраля, 31 марта, 30 апреля.
+func (p BillingPeriod) Add(t time.Time, n int) time.Time {
+	switch p.Unit {
+	case BillingWeek:
+		return t.AddDate(0, 0, 7*p.Count*n)
+	case BillingQuarter:
+		return addMonths(t, 3*p.Count*n)
+	case BillingYear:
+		return addMonths(t, 12*p.Count*n)
+	}
+	return addMonths(t, p.Count*n)
+}
+
+// addMonths — t через months месяцев. В отличие от AddDate, не переносит лишние дни
+// в следующий месяц (31 января + 1 месяц = 3 марта), а ограничивает день длиной месяца.
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	return first.AddDate(0, 0, min(t.Day(), last)-1)
+}
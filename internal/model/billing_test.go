@@ -0,0 +1,91 @@
+package model_test
+
+import (
+	"subscription/internal/model"
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		t.Fatalf("parse date %q: %v", s, err)
+	}
+	return d
+}
+
+func TestBillingPeriodAdd(t *testing.T) {
+	monthly := model.BillingPeriod{Unit: model.BillingMonth, Count: 1}
+	tests := []struct {
+		name   string
+		period model.BillingPeriod
+		anchor string
+		want   []string // даты списаний n = 0, 1, 2…
+	}{
+		{
+			name:   "31st through short months",
+			period: monthly,
+			anchor: "2025-01-31",
+			want:   []string{"2025-01-31", "2025-02-28", "2025-03-31", "2025-04-30", "2025-05-31"},
+		},
+		{
+			name:   "30th through february",
+			period: monthly,
+			anchor: "2025-01-30",
+			want:   []string{"2025-01-30", "2025-02-28", "2025-03-30", "2025-04-30"},
+		},
+		{
+			name:   "29th in a common year",
+			period: monthly,
+			anchor: "2025-01-29",
+			want:   []string{"2025-01-29", "2025-02-28", "2025-03-29"},
+		},
+		{
+			name:   "29th in a leap year",
+			period: monthly,
+			anchor: "2024-01-29",
+			want:   []string{"2024-01-29", "2024-02-29", "2024-03-29"},
+		},
+		{
+			name:   "31st in a leap year",
+			period: monthly,
+			anchor: "2024-01-31",
+			want:   []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name:   "every two months",
+			period: model.BillingPeriod{Unit: model.BillingMonth, Count: 2},
+			anchor: "2023-12-31",
+			want:   []string{"2023-12-31", "2024-02-29", "2024-04-30", "2024-06-30"},
+		},
+		{
+			name:   "quarterly from the 30th",
+			period: model.BillingPeriod{Unit: model.BillingQuarter, Count: 1},
+			anchor: "2024-11-30",
+			want:   []string{"2024-11-30", "2025-02-28", "2025-05-30", "2025-08-30"},
+		},
+		{
+			name:   "yearly from the leap day",
+			period: model.BillingPeriod{Unit: model.BillingYear, Count: 1},
+			anchor: "2024-02-29",
+			want:   []string{"2024-02-29", "2025-02-28", "2026-02-28", "2027-02-28", "2028-02-29"},
+		},
+		{
+			name:   "weekly keeps exact days",
+			period: model.BillingPeriod{Unit: model.BillingWeek, Count: 1},
+			anchor: "2025-01-31",
+			want:   []string{"2025-01-31", "2025-02-07", "2025-02-14"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anchor := date(t, tt.anchor)
+			for n, want := range tt.want {
+				if got := tt.period.Add(anchor, n); !got.Equal(date(t, want)) {
+					t.Errorf("Add(%s, %d) = %s, want %s", tt.anchor, n, got.Format(time.DateOnly), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Budget — месячный бюджет пользователя на подписки в минимальных единицах Currency.
+// Пустая Category — бюджет на все подписки пользователя, иначе — только на подписки этой категории.
+type Budget struct {
+	ID       int    `json:"id"`
+	UserID   string `json:"user_id"`
+	Category string `json:"category,omitempty"`
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// BudgetList — бюджеты, упорядоченные по user_id и категории.
+type BudgetList struct {
+	Items []Budget `json:"items"`
+}
+
+// BudgetThresholds — пороги в процентах бюджета, по возрастанию. Когда траты за месяц
+// достигают порога, пишется уведомление BudgetAlert.
+var BudgetThresholds = []int{80, 100}
+
+// BudgetStatus — траты за месяц против бюджета. Spent считается как сумма подписок
+// (Sum в режиме accrual) в валюте бюджета.
+type BudgetStatus struct {
+	Budget  Budget `json:"budget"`
+	Month   string `json:"month"` // MM-YYYY
+	Spent   int    `json:"spent"`
+	Percent int    `json:"percent"` // Spent от Amount, округлено вниз
+}
+
+// BudgetAlert — уведомление о том, что траты за месяц достигли порога бюджета. На бюджет,
+// месяц и порог пишется одно уведомление; SubscriptionID — подписка, создание или изменение
+// которой довело траты до порога.
+type BudgetAlert struct {
+	ID             int       `json:"id"`
+	BudgetID       int       `json:"budget_id"`
+	UserID         string    `json:"user_id"`
+	Category       string    `json:"category,omitempty"`
+	Month          time.Time `json:"month"`
+	Threshold      int       `json:"threshold"`
+	Spent          int       `json:"spent"`
+	Amount         int       `json:"amount"`
+	Currency       string    `json:"currency"`
+	SubscriptionID int       `json:"subscription_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MarshalJSON отдаёт Month в формате MM-YYYY, как остальные месяцы API; в хранилище он
+// остаётся датой — первым числом месяца.
+func (a BudgetAlert) MarshalJSON() ([]byte, error) {
+	type alert BudgetAlert
+	return json.Marshal(struct {
+		alert
+		Month string `json:"month"`
+	}{alert(a), a.Month.Format(MonthLayout)})
+}
+
+// BudgetAlertList — уведомления бюджета в порядке записи.
+type BudgetAlertList struct {
+	Items []BudgetAlert `json:"items"`
+}
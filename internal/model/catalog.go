@@ -0,0 +1,25 @@
+package model
+
+import "strings"
+
+// Service — запись каталога сервисов. Name — каноническое название: подписки на сервис
+// хранятся под ним, поэтому списки и суммы не дробятся на разные написания.
+// Aliases — другие написания, которые сводятся к Name.
+type Service struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Aliases   []string `json:"aliases"`
+	Category  string   `json:"category,omitempty"`
+	VendorURL string   `json:"vendor_url,omitempty"`
+}
+
+// ServiceList — каталог сервисов, упорядоченный по названию.
+type ServiceList struct {
+	Items []Service `json:"items"`
+}
+
+// ServiceKey — ключ, по которому сравниваются названия и псевдонимы: без учёта регистра
+// и пробелов по краям, так что «Netflix», «netflix» и «NETFLIX » — один сервис.
+func ServiceKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
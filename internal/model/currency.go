@@ -0,0 +1,46 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultCurrency — валюта подписок, созданных без явной валюты.
+const DefaultCurrency = "RUB"
+
+// currencyExponents — число знаков минимальной единицы для поддерживаемых валют ISO 4217:
+// у рубля 2 (копейки), у иены 0.
+var currencyExponents = map[string]int{
+	"AED": 2, "AMD": 2, "AUD": 2, "AZN": 2, "BYN": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"CZK": 2, "EUR": 2, "GBP": 2, "GEL": 2, "HKD": 2, "ILS": 2, "INR": 2, "JPY": 0,
+	"KGS": 2, "KRW": 0, "KZT": 2, "NOK": 2, "PLN": 2, "RSD": 2, "RUB": 2, "SEK": 2,
+	"SGD": 2, "THB": 2, "TRY": 2, "UAH": 2, "USD": 2, "UZS": 2,
+}
+
+// MaxAmountMinor — наибольшая сумма в минимальных единицах валюты для цен, вводных цен, тарифов
+// и бюджетов: 10 млрд в валюте с копейками. Итоги за период с таким запасом не выходят за BIGINT.
+const MaxAmountMinor = 1_000_000_000_000
+
+// CurrencySupported сообщает, знает ли сервис валюту с таким кодом.
+func CurrencySupported(code string) bool {
+	_, ok := currencyExponents[code]
+	return ok
+}
+
+// MinorUnits — сколько минимальных единиц в одной единице валюты (100 для рубля).
+func MinorUnits(code string) float64 {
+	return math.Pow10(currencyExponents[code])
+}
+
+// FXRate — курс: начиная с ValidFrom одна единица Base стоит Rate единиц Quote.
+type FXRate struct {
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Rate      float64   `json:"rate"`
+	ValidFrom time.Time `json:"valid_from"`
+}
+
+// FXRateList — курсы валют в ответе API.
+type FXRateList struct {
+	Items []FXRate `json:"items"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType — вид изменения подписки в журнале.
+type EventType string
+
+const (
+	EventCreate      EventType = "create"
+	EventUpdate      EventType = "update"
+	EventDelete      EventType = "delete"
+	EventRestore     EventType = "restore"
+	EventPriceChange EventType = "price_change"
+)
+
+// SubscriptionEvent — запись журнала изменений подписки. Before и After — подписка в JSON
+// до и после изменения (Before пуст у create), у price_change — записи графика цен.
+// Хранятся как есть, чтобы история не зависела от того, как с тех пор поменялась модель.
+type SubscriptionEvent struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int             `json:"subscription_id"`
+	Type           EventType       `json:"type"`
+	Actor          string          `json:"actor,omitempty"`
+	RequestID      string          `json:"request_id,omitempty"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// SubscriptionHistory — журнал изменений одной подписки, от старых записей к новым.
+type SubscriptionHistory struct {
+	Items []SubscriptionEvent `json:"items"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// MatchMode — способ сравнения названия сервиса в фильтре.
+type MatchMode string
+
+const (
+	MatchExact      MatchMode = "exact"  // точное совпадение (по умолчанию)
+	MatchIgnoreCase MatchMode = "icase"  // совпадение без учёта регистра
+	MatchPrefix     MatchMode = "prefix" // префикс без учёта регистра
+)
+
+// Period — отрезок месяцев [From, To], обе границы включительно.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// SubscriptionFilter — условия отбора подписок. Общий для хендлера, сервиса и хранилищ:
+// новый фильтр добавляется полем сюда, а не параметром в сигнатуры.
+// Нулевое значение поля означает «не фильтровать»; внутри одного поля значения объединяются по ИЛИ,
+// между полями — по И.
+type SubscriptionFilter struct {
+	UserIDs          []string
+	ServiceNames     []string
+	ServiceNameMatch MatchMode
+	Categories       []string
+	Tags             []string // подписка с любой из меток
+	PriceMin         *int
+	PriceMax         *int
+	ActiveWithin     *Period // подписка активна хотя бы один месяц внутри периода
+	HasEndDate       *bool
+	IncludeDeleted   bool // учитывать и мягко удалённые подписки; по умолчанию они скрыты
+	// Adjusted — подписки с пробным периодом, вводной ценой или графиком цен (true) либо без них (false).
+	// Из запроса не заполняется: по нему Sum делит подписки между агрегатом хранилища и подсчётом в сервисе.
+	Adjusted *bool
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// IdempotencyRecord — запись о запросе с Idempotency-Key.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string // sha256 метода, пути и тела запроса
+	// Response — сохранённый ответ; nil, пока первый запрос ещё выполняется.
+	Response  *IdempotentResponse
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// IdempotentResponse — ответ, который повторяется для запросов с тем же ключом.
+type IdempotentResponse struct {
+	StatusCode int
+	Header     map[string][]string
+	Body       []byte
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SortField — поле, по которому сортируется список подписок.
+// Значения совпадают с именами колонок в хранилище.
+type SortField string
+
+const (
+	SortByID          SortField = "id"
+	SortByPrice       SortField = "price"
+	SortByStartDate   SortField = "start_date"
+	SortByServiceName SortField = "service_name"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+const sortDateLayout = "2006-01-02"
+
+// Page — параметры страницы при постраничной выдаче (keyset-пагинация).
+// Записи упорядочены по Sort, при равенстве — по id в том же направлении.
+type Page struct {
+	Limit int
+	Sort  SortField
+	Desc  bool
+	After *Cursor // nil — первая страница
+}
+
+// Cursor — позиция последней отданной записи. Клиент видит его как непрозрачную строку.
+type Cursor struct {
+	Sort  string `json:"s"` // сортировка, для которой выдан курсор, например "price:desc"
+	Value string `json:"v"` // значение поля сортировки у последней записи
+	ID    int    `json:"id"`
+}
+
+// SubscriptionList — страница списка подписок.
+type SubscriptionList struct {
+	Items      []*Subscription `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// ParseSort разбирает параметр sort вида "field" или "field:asc|desc".
+func ParseSort(s string) (SortField, bool, error) {
+	if s == "" {
+		return SortByID, false, nil
+	}
+	field, dir, _ := strings.Cut(s, ":")
+	var desc bool
+	switch dir {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return "", false, fmt.Errorf("invalid sort direction: %q", dir)
+	}
+	switch f := SortField(field); f {
+	case SortByID, SortByPrice, SortByStartDate, SortByServiceName:
+		return f, desc, nil
+	}
+	return "", false, fmt.Errorf("invalid sort field: %q", field)
+}
+
+// SortKey — каноничное представление сортировки страницы.
+func (p Page) SortKey() string {
+	if p.Desc {
+		return string(p.Sort) + ":desc"
+	}
+	return string(p.Sort) + ":asc"
+}
+
+// ParseValue переводит значение из курсора в тип поля сортировки.
+func (f SortField) ParseValue(v string) (any, error) {
+	switch f {
+	case SortByID:
+		return nil, nil
+	case SortByPrice:
+		return strconv.Atoi(v)
+	case SortByStartDate:
+		return time.Parse(sortDateLayout, v)
+	case SortByServiceName:
+		return v, nil
+	}
+	return nil, fmt.Errorf("unknown sort field: %q", f)
+}
+
+// NextCursor строит курсор, указывающий на подписку sub, для сортировки страницы p.
+func (p Page) NextCursor(sub *Subscription) Cursor {
+	c := Cursor{Sort: p.SortKey(), ID: sub.ID}
+	switch p.Sort {
+	case SortByPrice:
+		c.Value = strconv.Itoa(sub.Price)
+	case SortByStartDate:
+		c.Value = sub.StartDate.Format(sortDateLayout)
+	case SortByServiceName:
+		c.Value = sub.ServiceName
+	}
+	return c
+}
+
+// Encode упаковывает курсор в строку для клиента.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor распаковывает курсор и проверяет, что он выдан для той же сортировки, что у страницы p.
+func DecodeCursor(s string, p Page) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	if c.Sort != p.SortKey() {
+		return nil, errors.New("cursor does not match sort")
+	}
+	if _, err := p.Sort.ParseValue(c.Value); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	return &c, nil
+}
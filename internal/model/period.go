@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// MonthLayout — формат месяца во внешнем API (MM-YYYY).
+const MonthLayout = "01-2006"
+
+// MonthIndex переводит дату в порядковый номер месяца (год*12 + месяц),
+// чтобы считать разницу между месяцами простой арифметикой.
+func MonthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// MonthFromIndex — обратное преобразование к MonthIndex: первое число месяца в UTC.
+func MonthFromIndex(i int) time.Time {
+	return time.Date(i/12, time.Month(i%12+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// MonthsBetween возвращает количество месяцев в отрезке [from, to] включительно.
+// Если to раньше from — 0.
+func MonthsBetween(from, to time.Time) int {
+	n := MonthIndex(to) - MonthIndex(from) + 1
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// ActiveMonths — сколько месяцев подписка была активна внутри периода [from, to].
+// Период подписки обрезается по start_date/end_date, end_date считается включительно.
+func (s Subscription) ActiveMonths(from, to time.Time) int {
+	start := from
+	if s.StartDate.After(start) {
+		start = s.StartDate
+	}
+	end := to
+	if s.EndDate != nil && s.EndDate.Before(end) {
+		end = *s.EndDate
+	}
+	return MonthsBetween(start, end)
+}
@@ -0,0 +1,16 @@
+package model
+
+// Plan — тариф сервиса из каталога (например, Spotify Individual, Duo, Family) с прейскурантной
+// ценой за период списания в минимальных единицах Currency.
+type Plan struct {
+	ID        int    `json:"id"`
+	ServiceID int    `json:"service_id"`
+	Name      string `json:"name"`
+	Price     int    `json:"price_minor"`
+	Currency  string `json:"currency"`
+}
+
+// PlanList — тарифы одного сервиса, упорядоченные по названию.
+type PlanList struct {
+	Items []Plan `json:"items"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// PriceChange — запланированное изменение цены: с месяца EffectiveFrom подписка стоит Price
+// за период списания (Subscription.BillingPeriod), как и Subscription.Price.
+type PriceChange struct {
+	SubscriptionID int       `json:"subscription_id"`
+	EffectiveFrom  time.Time `json:"effective_from"`
+	Price          int       `json:"price_minor"`
+}
+
+// PriceSchedule — изменения цены одной подписки по возрастанию EffectiveFrom.
+type PriceSchedule []PriceChange
+
+// PriceAt — цена в месяце month: последнее изменение, вступившее в силу не позже month,
+// а до первого изменения — base (Subscription.Price).
+func (p PriceSchedule) PriceAt(base int, month time.Time) int {
+	m := MonthIndex(month)
+	price := base
+	for _, ch := range p {
+		if MonthIndex(ch.EffectiveFrom) > m {
+			break
+		}
+		price = ch.Price
+	}
+	return price
+}
+
+// GroupPriceChanges раскладывает изменения цен по подпискам, каждую — по возрастанию даты.
+func GroupPriceChanges(changes []PriceChange) map[int]PriceSchedule {
+	res := make(map[int]PriceSchedule)
+	for _, ch := range changes {
+		res[ch.SubscriptionID] = append(res[ch.SubscriptionID], ch)
+	}
+	for _, p := range res {
+		slices.SortFunc(p, func(a, b PriceChange) int { return a.EffectiveFrom.Compare(b.EffectiveFrom) })
+	}
+	return res
+}
+
+// PriceChangeList — график цен подписки в ответе API.
+type PriceChangeList struct {
+	Items []PriceChange `json:"items"`
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// TrialUnit — единица длины пробного периода.
+type TrialUnit string
+
+const (
+	TrialDay   TrialUnit = "day"
+	TrialMonth TrialUnit = "month"
+)
+
+// maxTrialCount — наибольшая длина пробного периода: не больше 10 лет, как и период списания.
+var maxTrialCount = map[TrialUnit]int{
+	TrialDay:   3650,
+	TrialMonth: 120,
+}
+
+// Trial — бесплатный пробный период с start_date: Count единиц Unit.
+type Trial struct {
+	Unit  TrialUnit `json:"unit"`
+	Count int       `json:"count"`
+}
+
+// Validate проверяет единицу и длину пробного периода (от 1 до maxTrialCount).
+func (t Trial) Validate() error {
+	limit, ok := maxTrialCount[t.Unit]
+	if !ok {
+		return fmt.Errorf("unit must be day or month")
+	}
+	if t.Count < 1 {
+		return fmt.Errorf("count must be positive")
+	}
+	if t.Count > limit {
+		return fmt.Errorf("count must be at most %d for %s (10 years)", limit, t.Unit)
+	}
+	return nil
+}
+
+// IntroPrice — вводная цена: первые Periods периодов списания после пробного периода стоят Price.
+type IntroPrice struct {
+	Price   int `json:"price_minor"`
+	Periods int `json:"periods"`
+}
+
+// Validate проверяет цену и число периодов: вводная цена действует не дольше 10 лет
+// периодов billing. Неверный billing ограничение не задаёт: его отклонит BillingPeriod.Validate.
+func (p IntroPrice) Validate(billing BillingPeriod) error {
+	if p.Price < 0 {
+		return fmt.Errorf("price_minor cannot be negative")
+	}
+	if p.Price > MaxAmountMinor {
+		return fmt.Errorf("price_minor must be at most %d", MaxAmountMinor)
+	}
+	if p.Periods < 1 {
+		return fmt.Errorf("periods must be positive")
+	}
+	billing = billing.OrDefault()
+	limit, ok := maxBillingCount[billing.Unit]
+	if !ok || billing.Count < 1 || billing.Count > limit {
+		return nil
+	}
+	if p.Periods > limit/billing.Count {
+		return fmt.Errorf("periods must be at most %d for this billing period (10 years)", limit/billing.Count)
+	}
+	return nil
+}
+
+// PaidFrom — дата окончания пробного периода, с неё начинаются списания.
+func (s Subscription) PaidFrom() time.Time {
+	if s.Trial == nil {
+		return s.StartDate
+	}
+	if s.Trial.Unit == TrialDay {
+		return s.StartDate.AddDate(0, 0, s.Trial.Count)
+	}
+	return s.StartDate.AddDate(0, s.Trial.Count, 0)
+}
+
+// IntroUntil — дата, с которой вводная цена сменяется обычной.
+func (s Subscription) IntroUntil() time.Time {
+	if s.Intro == nil {
+		return s.PaidFrom()
+	}
+	return s.BillingPeriod.OrDefault().Add(s.PaidFrom(), s.Intro.Periods)
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// MaxRenewalMonths — наибольшее число месяцев в календаре списаний: недельная подписка
+// даёт по элементу на каждое списание, и без предела один запрос строил бы их сотни тысяч.
+const MaxRenewalMonths = 36
+
+// Renewal — одно списание по подписке: дата и сумма в валюте подписки.
+// Intro — списание по вводной цене.
+type Renewal struct {
+	SubscriptionID int       `json:"subscription_id"`
+	ServiceName    string    `json:"service_name"`
+	Date           time.Time `json:"date"`
+	Price          int       `json:"price_minor"`
+	Currency       string    `json:"currency"`
+	Intro          bool      `json:"intro,omitempty"`
+}
+
+// RenewalCalendar — списания пользователя за период по датам. Total — их сумма в Currency,
+// та же, что у Sum в режиме cash за тот же период.
+type RenewalCalendar struct {
+	UserID   string    `json:"user_id"`
+	Currency string    `json:"currency"`
+	Total    int       `json:"total"`
+	Items    []Renewal `json:"items"`
+}
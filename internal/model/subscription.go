@@ -1,12 +1,160 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
+// Subscription — подписка пользователя. Version растёт на каждом обновлении и отдаётся
+// в HTTP как ETag; в UpdateSubscription хранилища это ожидаемая версия (0 — без проверки).
+// DeletedAt заполнено у мягко удалённой подписки. Price — цена за BillingPeriod
+// в минимальных единицах Currency (копейках, центах). Trial и Intro — пробный период
+// и вводная цена, nil — их нет. PlanID — тариф сервиса из каталога, nil — без тарифа.
+// Category и Tags — статья расходов и свободные метки для отчётов, в нижнем регистре.
+// PriceFromPlan — цена в запросе не указана и при записи берётся из тарифа PlanID вместе с валютой;
+// в ответы и хранилище флаг не попадает. Дальнейшие изменения цены тарифа на подписку не влияют.
 type Subscription struct {
 	ID          int        `json:"id"`
 	ServiceName string     `json:"service_name"`
-	Price       int        `json:"price"`
+	Price       int        `json:"price_minor"`
 	UserID      string     `json:"user_id"`
 	StartDate   time.Time  `json:"start_date"`
 	EndDate     *time.Time `json:"end_date,omitempty"`
+	Version     int        `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+
+	BillingPeriod BillingPeriod `json:"billing_period"`
+	Currency      string        `json:"currency"`
+	Trial         *Trial        `json:"trial,omitempty"`
+	Intro         *IntroPrice   `json:"intro_price,omitempty"`
+	PlanID        *int          `json:"plan_id,omitempty"`
+	Category      string        `json:"category,omitempty"`
+	Tags          []string      `json:"tags,omitempty"`
+
+	PriceFromPlan bool `json:"-"`
+}
+
+// IfMatch — версии подписки из заголовка If-Match. Нулевое значение (заголовка нет или «*»)
+// подходит к любой версии; иначе текущая версия должна быть среди Versions.
+type IfMatch struct {
+	Set      bool
+	Versions []int
+}
+
+// Matches проверяет, подходит ли текущая версия подписки.
+func (m IfMatch) Matches(version int) bool {
+	return !m.Set || slices.Contains(m.Versions, version)
+}
+
+// SubscriptionPatch — частичное обновление подписки (JSON Merge Patch, RFC 7396):
+// nil-поле оставляет значение как есть. Для end_date отдельно различаются
+// «не передано» и «передан null»: ClearEndDate очищает дату.
+type SubscriptionPatch struct {
+	ServiceName  *string
+	Price        *int
+	UserID       *string
+	StartDate    *time.Time
+	EndDate      *time.Time
+	ClearEndDate bool
+	// BillingUnit и BillingCount — поля billing_period, объект сливается по полям.
+	BillingUnit  *BillingUnit
+	BillingCount *int
+	Currency     *string
+	// Trial и Intro сливаются по полям так же; ClearTrial и ClearIntro — передан null.
+	TrialUnit    *TrialUnit
+	TrialCount   *int
+	ClearTrial   bool
+	IntroPrice   *int
+	IntroPeriods *int
+	ClearIntro   bool
+	// PlanID без Price переводит подписку на цену нового тарифа; ClearPlan — передан null.
+	PlanID    *int
+	ClearPlan bool
+	// Tags заменяет метки целиком: массивы merge patch не сливает; пустой список убирает все.
+	Category *string
+	Tags     *[]string
+}
+
+// Apply накладывает изменения на копию подписки.
+func (p SubscriptionPatch) Apply(sub Subscription) Subscription {
+	if p.ServiceName != nil {
+		sub.ServiceName = *p.ServiceName
+	}
+	if p.Price != nil {
+		sub.Price = *p.Price
+	}
+	if p.UserID != nil {
+		sub.UserID = *p.UserID
+	}
+	if p.StartDate != nil {
+		sub.StartDate = *p.StartDate
+	}
+	switch {
+	case p.ClearEndDate:
+		sub.EndDate = nil
+	case p.EndDate != nil:
+		end := *p.EndDate
+		sub.EndDate = &end
+	}
+	if p.BillingUnit != nil {
+		sub.BillingPeriod.Unit = *p.BillingUnit
+	}
+	if p.BillingCount != nil {
+		sub.BillingPeriod.Count = *p.BillingCount
+	}
+	if p.Currency != nil {
+		sub.Currency = *p.Currency
+	}
+	switch {
+	case p.ClearTrial:
+		sub.Trial = nil
+	case p.TrialUnit != nil || p.TrialCount != nil:
+		var trial Trial
+		if sub.Trial != nil {
+			trial = *sub.Trial
+		}
+		if p.TrialUnit != nil {
+			trial.Unit = *p.TrialUnit
+		}
+		if p.TrialCount != nil {
+			trial.Count = *p.TrialCount
+		}
+		sub.Trial = &trial
+	}
+	switch {
+	case p.ClearIntro:
+		sub.Intro = nil
+	case p.IntroPrice != nil || p.IntroPeriods != nil:
+		var intro IntroPrice
+		if sub.Intro != nil {
+			intro = *sub.Intro
+		}
+		if p.IntroPrice != nil {
+			intro.Price = *p.IntroPrice
+		}
+		if p.IntroPeriods != nil {
+			intro.Periods = *p.IntroPeriods
+		}
+		sub.Intro = &intro
+	}
+	switch {
+	case p.ClearPlan:
+		sub.PlanID = nil
+	case p.PlanID != nil:
+		sub.PlanID = p.PlanID
+		if p.Price == nil {
+			sub.PriceFromPlan = true
+			if p.Currency == nil {
+				// Без явной валюты подписка переходит на валюту тарифа вместе с ценой.
+				sub.Currency = ""
+			}
+		}
+	}
+	if p.Category != nil {
+		sub.Category = *p.Category
+	}
+	if p.Tags != nil {
+		sub.Tags = *p.Tags
+	}
+	return sub
 }
@@ -0,0 +1,144 @@
+package model
+
+import "time"
+
+// SumMethodAccrual — стоимость каждого месяца, в который подписка активна внутри периода, — её цена,
+// приведённая к месяцу. Что ещё повлияло на сумму, перечисляет Summary.Adjustments.
+const SumMethodAccrual = "monthly_accrual"
+
+// SumMethodCharges — стоимость считается как сумма списаний, пришедшихся на период.
+const SumMethodCharges = "price_x_charges"
+
+// Поправки к базовому способу подсчёта, которые попали в Summary.Adjustments.
+const (
+	AdjustmentFX           = "fx"            // суммы пересчитаны из другой валюты
+	AdjustmentTrial        = "trial"         // у подписок есть пробный период
+	AdjustmentIntroPrice   = "intro_price"   // у подписок есть вводная цена
+	AdjustmentPriceChanges = "price_changes" // у подписок есть график цен
+)
+
+// MaxSummaryMonths — наибольшая длина периода суммы в месяцах (10 лет). Сумма перебирает месяцы
+// периода, а MySQL строит их рекурсивным CTE с пределом глубины 1000.
+const MaxSummaryMonths = 120
+
+// SumMode — как стоимость подписки распределяется по месяцам.
+type SumMode string
+
+const (
+	// SumAccrual — равномерно: годовая подписка даёт 1/12 цены в каждый месяц.
+	SumAccrual SumMode = "accrual"
+	// SumCash — по датам списаний: годовая подписка — вся цена в месяц списания.
+	SumCash SumMode = "cash"
+)
+
+// ParseSumMode проверяет режим подсчёта; пустой — accrual.
+func ParseSumMode(s string) (SumMode, bool) {
+	switch m := SumMode(s); m {
+	case "":
+		return SumAccrual, true
+	case SumAccrual, SumCash:
+		return m, true
+	}
+	return "", false
+}
+
+// SummaryOptions — параметры подсчёта помимо фильтра и периода.
+type SummaryOptions struct {
+	GroupBy []GroupBy
+	Mode    SumMode
+	// Currency — валюта итога; пусто — валюта самих подписок, если она у всех одна.
+	Currency string
+	// IncludeItems — отдать вклад каждой подписки (не больше MaxPageLimit).
+	IncludeItems bool
+}
+
+// Summary — результат подсчёта стоимости подписок за период. Суммы — в минимальных единицах Currency.
+// Total, корзины и Cost подписок округляются каждый отдельно, поэтому их суммы могут на единицы расходиться.
+type Summary struct {
+	Total       int      `json:"total"`
+	Currency    string   `json:"currency"`
+	Months      int      `json:"months"` // длина запрошенного периода в месяцах
+	Method      string   `json:"method"`
+	Adjustments []string `json:"adjustments,omitempty"`
+	// Items заполняется, только если запрошен вклад подписок (IncludeItems); ItemsTruncated —
+	// подписок больше MaxPageLimit, и отданы первые по id.
+	Items          []SummaryItem `json:"items,omitempty"`
+	ItemsTruncated bool          `json:"items_truncated,omitempty"`
+	// Groups заполняется, только если запрошена группировка (group_by).
+	Groups []SummaryGroup `json:"groups,omitempty"`
+}
+
+// SummaryItem — вклад одной подписки в итоговую сумму. Price — в валюте подписки (Currency),
+// Cost — уже в валюте итога.
+type SummaryItem struct {
+	SubscriptionID int      `json:"subscription_id"`
+	ServiceName    string   `json:"service_name"`
+	UserID         string   `json:"user_id"`
+	PlanID         *int     `json:"plan_id,omitempty"`
+	Category       string   `json:"category,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Price          int      `json:"price_minor"` // цена в последнем активном месяце периода
+	Currency       string   `json:"currency"`
+	Months         int      `json:"months"`
+	Charges        int      `json:"charges,omitempty"` // число списаний в режиме cash
+	Cost           int      `json:"cost"`
+}
+
+// GroupBy — измерение, по которому можно разбить итоговую сумму.
+type GroupBy string
+
+const (
+	GroupByMonth       GroupBy = "month"
+	GroupByServiceName GroupBy = "service_name"
+	GroupByUserID      GroupBy = "user_id"
+	GroupByPlanID      GroupBy = "plan_id"
+	GroupByCategory    GroupBy = "category"
+	GroupByTag         GroupBy = "tag"
+)
+
+// ParseGroupBy проверяет, что измерение поддерживается.
+func ParseGroupBy(s string) (GroupBy, bool) {
+	switch g := GroupBy(s); g {
+	case GroupByMonth, GroupByServiceName, GroupByUserID, GroupByPlanID, GroupByCategory, GroupByTag:
+		return g, true
+	}
+	return "", false
+}
+
+// SummaryGroup — корзина сгруппированной суммы. Заполнены только поля,
+// по которым шла группировка; у корзины подписок без тарифа PlanID пуст.
+// При группировке по tag подписка попадает в корзину каждой своей метки, поэтому корзины
+// в сумме могут давать больше Total; подписки без меток — в корзине с пустым Tag.
+type SummaryGroup struct {
+	Month       string `json:"month,omitempty"` // MM-YYYY
+	ServiceName string `json:"service_name,omitempty"`
+	UserID      string `json:"user_id,omitempty"`
+	PlanID      *int   `json:"plan_id,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	Total       int    `json:"total"`
+}
+
+// SumQuery — запрос агрегата стоимости в хранилище (SubscriptionRepository.SumSubscriptions).
+// Считаются только подписки без пробного периода, вводной цены и графика цен: их стоимость в режиме
+// accrual — Price, приведённая к месяцу, на каждый активный месяц внутри [From, To].
+type SumQuery struct {
+	Filter  SubscriptionFilter
+	From    time.Time
+	To      time.Time
+	GroupBy []GroupBy
+}
+
+// SumRow — строка агрегата. Строки всегда разбиты по валюте, остальные поля заполнены для измерений
+// из GroupBy; Month — первое число месяца, нулевое без группировки по месяцу. При группировке по tag
+// подписка попадает в строку каждой своей метки, подписки без меток — в строку с пустым Tag.
+type SumRow struct {
+	Month       time.Time
+	ServiceName string
+	UserID      string
+	PlanID      *int
+	Category    string
+	Tag         string
+	Currency    string
+	Cost        float64 // в минимальных единицах Currency, без округления
+}
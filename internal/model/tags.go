@@ -0,0 +1,10 @@
+package model
+
+// Ограничения категории и меток подписки. Категория — как у сервиса в каталоге, от которого
+// она наследуется. Метки короткие, чтобы их список целиком помещался в GROUP_CONCAT MySQL
+// (group_concat_max_len по умолчанию 1024).
+const (
+	MaxCategoryLength = 255
+	MaxTags           = 20
+	MaxTagLength      = 40
+)
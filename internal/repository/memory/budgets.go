@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+func (s *Storage) CreateBudget(ctx context.Context, b model.Budget) (model.Budget, error) {
+	defer s.lock()()
+
+	if s.budgetTaken(b) {
+		return b, service.ErrConflict
+	}
+	b.ID = s.nextBudgetID
+	s.nextBudgetID++
+	s.budgets[b.ID] = b
+	return b, nil
+}
+
+func (s *Storage) GetBudget(ctx context.Context, id int) (model.Budget, error) {
+	defer s.rlock()()
+
+	b, ok := s.budgets[id]
+	if !ok {
+		return model.Budget{}, service.ErrNotFound
+	}
+	return b, nil
+}
+
+func (s *Storage) ListBudgets(ctx context.Context, userID string) ([]model.Budget, error) {
+	defer s.rlock()()
+
+	var res []model.Budget
+	for _, b := range s.budgets {
+		if userID == "" || b.UserID == userID {
+			res = append(res, b)
+		}
+	}
+	slices.SortFunc(res, func(a, b model.Budget) int {
+		return cmp.Or(cmp.Compare(a.UserID, b.UserID), cmp.Compare(a.Category, b.Category))
+	})
+	return res, nil
+}
+
+func (s *Storage) UpdateBudget(ctx context.Context, b model.Budget) (model.Budget, error) {
+	defer s.lock()()
+
+	current, ok := s.budgets[b.ID]
+	if !ok {
+		return model.Budget{}, service.ErrNotFound
+	}
+	b.UserID = current.UserID
+	if s.budgetTaken(b) {
+		return b, service.ErrConflict
+	}
+	s.budgets[b.ID] = b
+	return b, nil
+}
+
+// DeleteBudget удаляет бюджет и, как ON DELETE CASCADE в БД, его уведомления.
+func (s *Storage) DeleteBudget(ctx context.Context, id int) error {
+	defer s.lock()()
+
+	if _, ok := s.budgets[id]; !ok {
+		return service.ErrNotFound
+	}
+	delete(s.budgets, id)
+	maps.DeleteFunc(s.budgetAlerts, func(_ int, a model.BudgetAlert) bool { return a.BudgetID == id })
+	return nil
+}
+
+func (s *Storage) AddBudgetAlert(ctx context.Context, alert model.BudgetAlert) (model.BudgetAlert, bool, error) {
+	defer s.lock()()
+
+	// Как UNIQUE (budget_id, month, threshold) в миграциях.
+	for _, other := range s.budgetAlerts {
+		if other.BudgetID == alert.BudgetID && other.Month.Equal(alert.Month) && other.Threshold == alert.Threshold {
+			return alert, false, nil
+		}
+	}
+	alert.ID = s.nextAlertID
+	s.nextAlertID++
+	s.budgetAlerts[alert.ID] = alert
+	return alert, true, nil
+}
+
+func (s *Storage) ListBudgetAlerts(ctx context.Context, budgetID int) ([]model.BudgetAlert, error) {
+	defer s.rlock()()
+
+	var res []model.BudgetAlert
+	for _, a := range s.budgetAlerts {
+		if a.BudgetID == budgetID {
+			res = append(res, a)
+		}
+	}
+	slices.SortFunc(res, func(a, b model.BudgetAlert) int { return cmp.Compare(a.ID, b.ID) })
+	return res, nil
+}
+
+// budgetTaken повторяет UNIQUE (user_id, category) из миграций.
+func (s *Storage) budgetTaken(b model.Budget) bool {
+	for _, other := range s.budgets {
+		if other.ID != b.ID && other.UserID == b.UserID && other.Category == b.Category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+func (s *Storage) CreateService(ctx context.Context, svc model.Service) (model.Service, error) {
+	defer s.lock()()
+
+	if err := s.checkServiceKeys(svc); err != nil {
+		return svc, err
+	}
+	svc.ID = s.nextServiceID
+	s.nextServiceID++
+	s.services[svc.ID] = cloneService(svc)
+	return svc, nil
+}
+
+func (s *Storage) GetService(ctx context.Context, id int) (model.Service, error) {
+	defer s.rlock()()
+
+	svc, ok := s.services[id]
+	if !ok {
+		return model.Service{}, service.ErrNotFound
+	}
+	return cloneService(svc), nil
+}
+
+func (s *Storage) FindServiceByKey(ctx context.Context, key string) (model.Service, error) {
+	defer s.rlock()()
+
+	for _, svc := range s.services {
+		if slices.Contains(serviceKeys(svc), key) {
+			return cloneService(svc), nil
+		}
+	}
+	return model.Service{}, service.ErrNotFound
+}
+
+func (s *Storage) ListServices(ctx context.Context) ([]model.Service, error) {
+	defer s.rlock()()
+
+	res := make([]model.Service, 0, len(s.services))
+	for _, svc := range s.services {
+		res = append(res, cloneService(svc))
+	}
+	slices.SortFunc(res, func(a, b model.Service) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return res, nil
+}
+
+func (s *Storage) UpdateService(ctx context.Context, svc model.Service) (model.Service, error) {
+	defer s.lock()()
+
+	if _, ok := s.services[svc.ID]; !ok {
+		return model.Service{}, service.ErrNotFound
+	}
+	if err := s.checkServiceKeys(svc); err != nil {
+		return svc, err
+	}
+	s.services[svc.ID] = cloneService(svc)
+	return svc, nil
+}
+
+func (s *Storage) DeleteService(ctx context.Context, id int) error {
+	defer s.lock()()
+
+	if _, ok := s.services[id]; !ok {
+		return service.ErrNotFound
+	}
+	delete(s.services, id)
+	for planID, plan := range s.plans {
+		if plan.ServiceID == id {
+			s.deletePlan(planID)
+		}
+	}
+	return nil
+}
+
+func (s *Storage) ListServiceSubscriptionIDs(ctx context.Context, name string) ([]int, error) {
+	defer s.rlock()()
+
+	var ids []int
+	for id, sub := range s.subs {
+		if sub.ServiceName == name && sub.DeletedAt == nil {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// checkServiceKeys — страховка уникальности ключей, как индексы services и service_aliases в БД.
+func (s *Storage) checkServiceKeys(svc model.Service) error {
+	keys := serviceKeys(svc)
+	for _, other := range s.services {
+		if other.ID != svc.ID && slices.ContainsFunc(serviceKeys(other), func(k string) bool {
+			return slices.Contains(keys, k)
+		}) {
+			return service.ErrConflict
+		}
+	}
+	return nil
+}
+
+// serviceKeys — ключи названия и псевдонимов сервиса.
+func serviceKeys(svc model.Service) []string {
+	keys := []string{model.ServiceKey(svc.Name)}
+	for _, alias := range svc.Aliases {
+		keys = append(keys, model.ServiceKey(alias))
+	}
+	return keys
+}
+
+// cloneService копирует псевдонимы, чтобы вызывающий не мог изменить хранимое значение.
+func cloneService(svc model.Service) model.Service {
+	svc.Aliases = slices.Clone(svc.Aliases)
+	if svc.Aliases == nil {
+		svc.Aliases = []string{}
+	}
+	return svc
+}
@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"context"
+	"subscription/internal/model"
+)
+
+func (s *Storage) AddSubscriptionEvent(ctx context.Context, ev model.SubscriptionEvent) error {
+	defer s.lock()()
+
+	s.nextEventID++
+	ev.ID = s.nextEventID
+	s.events = append(s.events, ev)
+	return nil
+}
+
+func (s *Storage) ListSubscriptionEvents(ctx context.Context, subscriptionID int) ([]model.SubscriptionEvent, error) {
+	defer s.rlock()()
+
+	var res []model.SubscriptionEvent
+	for _, ev := range s.events {
+		if ev.SubscriptionID == subscriptionID {
+			res = append(res, ev)
+		}
+	}
+	return res, nil
+}
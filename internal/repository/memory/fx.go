@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"subscription/internal/model"
+	"subscription/internal/service"
+	"time"
+)
+
+// fxKey — курс однозначно задаётся парой валют и датой начала действия.
+type fxKey struct {
+	base, quote string
+	validFrom   time.Time
+}
+
+// SetFXRates записывает курсы; курс той же пары с той же даты заменяется.
+func (s *Storage) SetFXRates(ctx context.Context, rates []model.FXRate) error {
+	defer s.lock()()
+
+	for _, r := range rates {
+		s.fxRates[fxKey{r.Base, r.Quote, r.ValidFrom}] = r
+	}
+	return nil
+}
+
+func (s *Storage) ListFXRates(ctx context.Context) ([]model.FXRate, error) {
+	defer s.rlock()()
+
+	res := make([]model.FXRate, 0, len(s.fxRates))
+	for _, r := range s.fxRates {
+		res = append(res, r)
+	}
+	slices.SortFunc(res, func(a, b model.FXRate) int {
+		return cmp.Or(
+			cmp.Compare(a.Base, b.Base),
+			cmp.Compare(a.Quote, b.Quote),
+			a.ValidFrom.Compare(b.ValidFrom),
+		)
+	})
+	return res, nil
+}
+
+func (s *Storage) DeleteFXRate(ctx context.Context, base, quote string, validFrom time.Time) error {
+	defer s.lock()()
+
+	key := fxKey{base, quote, validFrom}
+	if _, ok := s.fxRates[key]; !ok {
+		return service.ErrNotFound
+	}
+	delete(s.fxRates, key)
+	return nil
+}
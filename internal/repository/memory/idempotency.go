@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"subscription/internal/model"
+	"subscription/internal/service"
+	"time"
+)
+
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) (model.IdempotencyRecord, bool, error) {
+	defer s.lock()()
+
+	if existing, ok := s.keys[rec.Key]; ok && existing.ExpiresAt.After(rec.CreatedAt) {
+		return existing, false, nil
+	}
+	rec.Response = nil
+	s.keys[rec.Key] = rec
+	return rec, true, nil
+}
+
+func (s *Storage) SaveIdempotentResponse(ctx context.Context, key string, resp model.IdempotentResponse) error {
+	defer s.lock()()
+
+	rec, ok := s.keys[key]
+	if !ok {
+		return service.ErrNotFound
+	}
+	rec.Response = &resp
+	s.keys[key] = rec
+	return nil
+}
+
+// DeleteIdempotencyKey снимает только незавершённый резерв: сохранённый ответ живёт до истечения TTL.
+func (s *Storage) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	defer s.lock()()
+
+	if rec, ok := s.keys[key]; ok && rec.Response == nil {
+		delete(s.keys, key)
+	}
+	return nil
+}
+
+func (s *Storage) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int, error) {
+	defer s.lock()()
+
+	n := 0
+	for key, rec := range s.keys {
+		if !rec.ExpiresAt.After(now) {
+			delete(s.keys, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// проверяем имплиментацию
+var _ service.IdempotencyRepository = (*Storage)(nil)
@@ -0,0 +1,428 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"subscription/internal/model"
+	"subscription/internal/service"
+	"sync"
+	"time"
+)
+
+// Storage — хранилище подписок в памяти процесса. Повторяет семантику postgres.Storage
+// (включая запрет пересечения периодов), но ничего не сохраняет между перезапусками.
+// Подходит для локальной разработки, демо и тестов.
+type Storage struct {
+	mu *sync.RWMutex
+	*state
+	// inTx — Storage выдан в InTx: блокировка уже взята, методы её не трогают.
+	inTx bool
+}
+
+// state — данные хранилища; InTx снимает с них копию, чтобы откатить при ошибке.
+type state struct {
+	nextID  int
+	subs    map[int]model.Subscription
+	keys    map[string]model.IdempotencyRecord
+	prices  map[priceKey]model.PriceChange
+	fxRates map[fxKey]model.FXRate
+
+	nextServiceID int
+	services      map[int]model.Service
+	nextPlanID    int
+	plans         map[int]model.Plan
+
+	nextBudgetID int
+	budgets      map[int]model.Budget
+	nextAlertID  int
+	budgetAlerts map[int]model.BudgetAlert
+
+	nextEventID int64
+	events      []model.SubscriptionEvent
+}
+
+func New() *Storage {
+	return &Storage{
+		mu: &sync.RWMutex{},
+		state: &state{
+			nextID:  1,
+			subs:    make(map[int]model.Subscription),
+			keys:    make(map[string]model.IdempotencyRecord),
+			prices:  make(map[priceKey]model.PriceChange),
+			fxRates: make(map[fxKey]model.FXRate),
+
+			nextServiceID: 1,
+			services:      make(map[int]model.Service),
+			nextPlanID:    1,
+			plans:         make(map[int]model.Plan),
+
+			nextBudgetID: 1,
+			budgets:      make(map[int]model.Budget),
+			nextAlertID:  1,
+			budgetAlerts: make(map[int]model.BudgetAlert),
+		},
+	}
+}
+
+// InTx выполняет fn под эксклюзивной блокировкой всего хранилища и откатывает
+// изменения, если fn вернула ошибку.
+func (s *Storage) InTx(ctx context.Context, fn func(repo service.SubscriptionRepository) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+	defer s.lock()()
+
+	saved := s.state.snapshot()
+	if err := fn(&Storage{mu: s.mu, state: s.state, inTx: true}); err != nil {
+		*s.state = saved
+		return err
+	}
+	return nil
+}
+
+// LockSubscription — то же, что GetSubscription: InTx и так блокирует всё хранилище.
+func (s *Storage) LockSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error) {
+	return s.GetSubscription(ctx, id, includeDeleted)
+}
+
+// lock и rlock берут блокировку, если Storage не выдан в InTx, и возвращают функцию её снятия.
+func (s *Storage) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *Storage) rlock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// snapshot копирует данные. Записи хранятся по значению и не меняются на месте,
+// поэтому достаточно поверхностных копий map.
+func (st *state) snapshot() state {
+	saved := *st
+	saved.subs = maps.Clone(st.subs)
+	saved.keys = maps.Clone(st.keys)
+	saved.prices = maps.Clone(st.prices)
+	saved.fxRates = maps.Clone(st.fxRates)
+	saved.services = maps.Clone(st.services)
+	saved.plans = maps.Clone(st.plans)
+	saved.budgets = maps.Clone(st.budgets)
+	saved.budgetAlerts = maps.Clone(st.budgetAlerts)
+	// Журнал только дописывается: при откате хватит вернуть прежнюю длину.
+	saved.events = st.events[:len(st.events):len(st.events)]
+	return saved
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (s *Storage) CreateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error) {
+	defer s.lock()()
+
+	sub.ID = 0
+	sub.DeletedAt = nil
+	if id := s.findOverlap(sub); id != 0 {
+		return sub, &service.OverlapError{ConflictingID: id}
+	}
+
+	sub.ID = s.nextID
+	sub.Version = 1
+	s.nextID++
+	s.subs[sub.ID] = clone(sub)
+	return sub, nil
+}
+
+func (s *Storage) GetSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error) {
+	defer s.rlock()()
+
+	sub, ok := s.subs[id]
+	if !ok || (!includeDeleted && sub.DeletedAt != nil) {
+		return model.Subscription{}, service.ErrNotFound
+	}
+	return clone(sub), nil
+}
+
+func (s *Storage) ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Page) ([]*model.Subscription, error) {
+	defer s.rlock()()
+
+	var after *model.Subscription
+	if page.After != nil {
+		value, err := page.Sort.ParseValue(page.After.Value)
+		if err != nil {
+			return nil, err
+		}
+		after = cursorSubscription(page.Sort, value, page.After.ID)
+	}
+
+	var subs []*model.Subscription
+	for _, sub := range s.subs {
+		if !s.matches(filter, sub) {
+			continue
+		}
+		if after != nil {
+			c := compare(page.Sort, &sub, after)
+			if (!page.Desc && c <= 0) || (page.Desc && c >= 0) {
+				continue
+			}
+		}
+		sub := clone(sub)
+		subs = append(subs, &sub)
+	}
+
+	slices.SortFunc(subs, func(a, b *model.Subscription) int {
+		if page.Desc {
+			return compare(page.Sort, b, a)
+		}
+		return compare(page.Sort, a, b)
+	})
+	if page.Limit > 0 && len(subs) > page.Limit {
+		subs = subs[:page.Limit]
+	}
+	return subs, nil
+}
+
+func (s *Storage) ListActiveSubscriptions(ctx context.Context, filter model.SubscriptionFilter, startPeriod, endPeriod time.Time) ([]*model.Subscription, error) {
+	defer s.rlock()()
+
+	var subs []*model.Subscription
+	for _, sub := range s.subs {
+		if !s.matches(filter, sub) || sub.ActiveMonths(startPeriod, endPeriod) == 0 {
+			continue
+		}
+		sub := clone(sub)
+		subs = append(subs, &sub)
+	}
+	slices.SortFunc(subs, func(a, b *model.Subscription) int { return cmp.Compare(a.ID, b.ID) })
+	return subs, nil
+}
+
+func (s *Storage) UpdateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error) {
+	defer s.lock()()
+
+	current, ok := s.subs[sub.ID]
+	if !ok || current.DeletedAt != nil {
+		return model.Subscription{}, service.ErrNotFound
+	}
+	if sub.Version != 0 && sub.Version != current.Version {
+		return model.Subscription{}, service.ErrPreconditionFailed
+	}
+	if id := s.findOverlap(sub); id != 0 {
+		return model.Subscription{}, &service.OverlapError{ConflictingID: id}
+	}
+	sub.Version = current.Version + 1
+	sub.DeletedAt = nil
+	s.subs[sub.ID] = clone(sub)
+	return clone(sub), nil
+}
+
+// DeleteSubscription мягко удаляет подписку; version, если не 0, должна совпасть с текущей.
+func (s *Storage) DeleteSubscription(ctx context.Context, id, version int) error {
+	defer s.lock()()
+
+	current, ok := s.subs[id]
+	if !ok || current.DeletedAt != nil {
+		return service.ErrNotFound
+	}
+	if version != 0 && version != current.Version {
+		return service.ErrPreconditionFailed
+	}
+	now := time.Now().UTC()
+	current.DeletedAt = &now
+	current.Version++
+	s.subs[id] = current
+	return nil
+}
+
+// RestoreSubscription снимает отметку об удалении.
+func (s *Storage) RestoreSubscription(ctx context.Context, id int) (model.Subscription, error) {
+	defer s.lock()()
+
+	sub, ok := s.subs[id]
+	if !ok || sub.DeletedAt == nil {
+		return model.Subscription{}, service.ErrNotFound
+	}
+	if other := s.findOverlap(sub); other != 0 {
+		return model.Subscription{}, &service.OverlapError{ConflictingID: other}
+	}
+	sub.DeletedAt = nil
+	sub.Version++
+	s.subs[id] = sub
+	return clone(sub), nil
+}
+
+// PurgeDeletedSubscriptions окончательно удаляет подписки, удалённые не позже before.
+func (s *Storage) PurgeDeletedSubscriptions(ctx context.Context, before time.Time) (int, error) {
+	defer s.lock()()
+
+	n := 0
+	for id, sub := range s.subs {
+		if sub.DeletedAt != nil && !sub.DeletedAt.After(before) {
+			delete(s.subs, id)
+			// Как ON DELETE CASCADE в БД: график цен уходит вместе с подпиской.
+			maps.DeleteFunc(s.prices, func(k priceKey, _ model.PriceChange) bool { return k.subscriptionID == id })
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *Storage) FindOverlappingSubscription(ctx context.Context, sub model.Subscription) (int, error) {
+	defer s.rlock()()
+
+	return s.findOverlap(sub), nil
+}
+
+func (s *Storage) Close() error {
+	return nil
+}
+
+// findOverlap — аналог ограничения subscriptions_no_overlap, удалённые подписки не учитываются,
+// названия сравниваются по ключу сервиса. Вызывается под блокировкой.
+func (s *Storage) findOverlap(sub model.Subscription) int {
+	found := 0
+	key := model.ServiceKey(sub.ServiceName)
+	for id, other := range s.subs {
+		if id == sub.ID || other.DeletedAt != nil || other.UserID != sub.UserID || model.ServiceKey(other.ServiceName) != key {
+			continue
+		}
+		if overlaps(sub, other) && (found == 0 || id < found) {
+			found = id
+		}
+	}
+	return found
+}
+
+// overlaps — пересекаются ли периоды подписок; границы включительно, nil end_date — бессрочно.
+func overlaps(a, b model.Subscription) bool {
+	if a.EndDate != nil && a.EndDate.Before(b.StartDate) {
+		return false
+	}
+	if b.EndDate != nil && b.EndDate.Before(a.StartDate) {
+		return false
+	}
+	return true
+}
+
+// matches проверяет подписку на соответствие фильтру так же, как условия WHERE в postgres.Storage.
+func (st *state) matches(f model.SubscriptionFilter, sub model.Subscription) bool {
+	if len(f.UserIDs) > 0 && !slices.Contains(f.UserIDs, sub.UserID) {
+		return false
+	}
+	if len(f.ServiceNames) > 0 && !slices.ContainsFunc(f.ServiceNames, func(name string) bool {
+		switch f.ServiceNameMatch {
+		case model.MatchIgnoreCase:
+			return strings.EqualFold(sub.ServiceName, name)
+		case model.MatchPrefix:
+			return strings.HasPrefix(strings.ToLower(sub.ServiceName), strings.ToLower(name))
+		default:
+			return sub.ServiceName == name
+		}
+	}) {
+		return false
+	}
+	if len(f.Categories) > 0 && !slices.Contains(f.Categories, sub.Category) {
+		return false
+	}
+	if len(f.Tags) > 0 && !slices.ContainsFunc(f.Tags, func(tag string) bool { return slices.Contains(sub.Tags, tag) }) {
+		return false
+	}
+	if f.PriceMin != nil && sub.Price < *f.PriceMin {
+		return false
+	}
+	if f.PriceMax != nil && sub.Price > *f.PriceMax {
+		return false
+	}
+	if f.ActiveWithin != nil && sub.ActiveMonths(f.ActiveWithin.From, f.ActiveWithin.To) == 0 {
+		return false
+	}
+	if f.HasEndDate != nil && *f.HasEndDate != (sub.EndDate != nil) {
+		return false
+	}
+	if f.Adjusted != nil && *f.Adjusted != st.adjusted(sub) {
+		return false
+	}
+	if !f.IncludeDeleted && sub.DeletedAt != nil {
+		return false
+	}
+	return true
+}
+
+// adjusted — у подписки есть пробный период, вводная цена или график цен.
+func (st *state) adjusted(sub model.Subscription) bool {
+	if sub.Trial != nil || sub.Intro != nil {
+		return true
+	}
+	for key := range st.prices {
+		if key.subscriptionID == sub.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// compare сравнивает подписки по полю сортировки, при равенстве — по id.
+func compare(field model.SortField, a, b *model.Subscription) int {
+	var c int
+	switch field {
+	case model.SortByPrice:
+		c = cmp.Compare(a.Price, b.Price)
+	case model.SortByStartDate:
+		c = a.StartDate.Compare(b.StartDate)
+	case model.SortByServiceName:
+		c = strings.Compare(a.ServiceName, b.ServiceName)
+	}
+	return cmp.Or(c, cmp.Compare(a.ID, b.ID))
+}
+
+// cursorSubscription строит «подписку» с позицией курсора, чтобы сравнивать её через compare.
+func cursorSubscription(field model.SortField, value any, id int) *model.Subscription {
+	sub := &model.Subscription{ID: id}
+	switch field {
+	case model.SortByPrice:
+		sub.Price = value.(int)
+	case model.SortByStartDate:
+		sub.StartDate = value.(time.Time)
+	case model.SortByServiceName:
+		sub.ServiceName = value.(string)
+	}
+	return sub
+}
+
+// clone копирует подписку вместе с полями по указателю, чтобы вызывающий не мог изменить хранимое значение.
+func clone(sub model.Subscription) model.Subscription {
+	if sub.EndDate != nil {
+		end := *sub.EndDate
+		sub.EndDate = &end
+	}
+	if sub.DeletedAt != nil {
+		deleted := *sub.DeletedAt
+		sub.DeletedAt = &deleted
+	}
+	if sub.Trial != nil {
+		trial := *sub.Trial
+		sub.Trial = &trial
+	}
+	if sub.Intro != nil {
+		intro := *sub.Intro
+		sub.Intro = &intro
+	}
+	if sub.PlanID != nil {
+		planID := *sub.PlanID
+		sub.PlanID = &planID
+	}
+	sub.Tags = slices.Clone(sub.Tags)
+	return sub
+}
+
+// проверяем имплиментацию
+var _ service.SubscriptionRepository = (*Storage)(nil)
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+func (s *Storage) CreatePlan(ctx context.Context, plan model.Plan) (model.Plan, error) {
+	defer s.lock()()
+
+	if _, ok := s.services[plan.ServiceID]; !ok {
+		return plan, service.ErrNotFound
+	}
+	if s.planNameTaken(plan) {
+		return plan, service.ErrConflict
+	}
+	plan.ID = s.nextPlanID
+	s.nextPlanID++
+	s.plans[plan.ID] = plan
+	return plan, nil
+}
+
+func (s *Storage) GetPlan(ctx context.Context, id int) (model.Plan, error) {
+	defer s.rlock()()
+
+	plan, ok := s.plans[id]
+	if !ok {
+		return model.Plan{}, service.ErrNotFound
+	}
+	return plan, nil
+}
+
+func (s *Storage) ListPlans(ctx context.Context, serviceID int) ([]model.Plan, error) {
+	defer s.rlock()()
+
+	var res []model.Plan
+	for _, plan := range s.plans {
+		if plan.ServiceID == serviceID {
+			res = append(res, plan)
+		}
+	}
+	slices.SortFunc(res, func(a, b model.Plan) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return res, nil
+}
+
+func (s *Storage) UpdatePlan(ctx context.Context, plan model.Plan) (model.Plan, error) {
+	defer s.lock()()
+
+	current, ok := s.plans[plan.ID]
+	if !ok {
+		return model.Plan{}, service.ErrNotFound
+	}
+	plan.ServiceID = current.ServiceID
+	if s.planNameTaken(plan) {
+		return plan, service.ErrConflict
+	}
+	s.plans[plan.ID] = plan
+	return plan, nil
+}
+
+func (s *Storage) DeletePlan(ctx context.Context, id int) error {
+	defer s.lock()()
+
+	if _, ok := s.plans[id]; !ok {
+		return service.ErrNotFound
+	}
+	s.deletePlan(id)
+	return nil
+}
+
+// deletePlan удаляет тариф и отвязывает от него подписки, как ON DELETE SET NULL в БД.
+func (s *Storage) deletePlan(id int) {
+	delete(s.plans, id)
+	for subID, sub := range s.subs {
+		if sub.PlanID != nil && *sub.PlanID == id {
+			sub.PlanID = nil
+			s.subs[subID] = sub
+		}
+	}
+}
+
+// planNameTaken повторяет UNIQUE (service_id, name) из миграций.
+func (s *Storage) planNameTaken(plan model.Plan) bool {
+	for _, other := range s.plans {
+		if other.ID != plan.ID && other.ServiceID == plan.ServiceID && other.Name == plan.Name {
+			return true
+		}
+	}
+	return false
+}
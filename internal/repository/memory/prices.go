@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"subscription/internal/model"
+)
+
+// SetPriceChange записывает изменение цены; изменение с того же месяца заменяется.
+func (s *Storage) SetPriceChange(ctx context.Context, ch model.PriceChange) error {
+	defer s.lock()()
+
+	s.prices[priceKey{ch.SubscriptionID, model.MonthIndex(ch.EffectiveFrom)}] = ch
+	return nil
+}
+
+func (s *Storage) ListPriceChanges(ctx context.Context, subscriptionIDs []int) ([]model.PriceChange, error) {
+	defer s.rlock()()
+
+	var res []model.PriceChange
+	for key, ch := range s.prices {
+		if slices.Contains(subscriptionIDs, key.subscriptionID) {
+			res = append(res, ch)
+		}
+	}
+	slices.SortFunc(res, func(a, b model.PriceChange) int {
+		return cmp.Or(
+			cmp.Compare(a.SubscriptionID, b.SubscriptionID),
+			a.EffectiveFrom.Compare(b.EffectiveFrom),
+		)
+	})
+	return res, nil
+}
+
+// priceKey — изменение цены однозначно задаётся подпиской и месяцем.
+type priceKey struct {
+	subscriptionID int
+	month          int
+}
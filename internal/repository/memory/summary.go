@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"subscription/internal/model"
+)
+
+// sumKey — измерения строки агрегата; незаполненные остаются нулевыми, planID 0 — без тарифа.
+type sumKey struct {
+	currency    string
+	month       int
+	serviceName string
+	userID      string
+	planID      int
+	category    string
+	tag         string
+}
+
+// SumSubscriptions считает стоимость подписок без поправок так же, как запрос postgres.Storage.
+func (s *Storage) SumSubscriptions(ctx context.Context, q model.SumQuery) ([]model.SumRow, error) {
+	defer s.rlock()()
+
+	filter, adjusted := q.Filter, false
+	filter.Adjusted = &adjusted
+	filter.ActiveWithin = &model.Period{From: q.From, To: q.To}
+	byMonth := slices.Contains(q.GroupBy, model.GroupByMonth)
+
+	costs := make(map[sumKey]float64)
+	var order []sumKey
+	add := func(key sumKey, cost float64) {
+		if _, ok := costs[key]; !ok {
+			order = append(order, key)
+		}
+		costs[key] += cost
+	}
+	for _, sub := range s.subs {
+		if !s.matches(filter, sub) {
+			continue
+		}
+		key := sumKey{currency: sub.Currency, month: -1}
+		if slices.Contains(q.GroupBy, model.GroupByServiceName) {
+			key.serviceName = sub.ServiceName
+		}
+		if slices.Contains(q.GroupBy, model.GroupByUserID) {
+			key.userID = sub.UserID
+		}
+		if slices.Contains(q.GroupBy, model.GroupByPlanID) && sub.PlanID != nil {
+			key.planID = *sub.PlanID
+		}
+		if slices.Contains(q.GroupBy, model.GroupByCategory) {
+			key.category = sub.Category
+		}
+		tags := []string{""}
+		if slices.Contains(q.GroupBy, model.GroupByTag) && len(sub.Tags) > 0 {
+			tags = sub.Tags
+		}
+
+		monthly := float64(sub.Price) * sub.BillingPeriod.OrDefault().MonthlyShare()
+		for _, tag := range tags {
+			key.tag = tag
+			if !byMonth {
+				add(key, monthly*float64(sub.ActiveMonths(q.From, q.To)))
+				continue
+			}
+			for idx := model.MonthIndex(q.From); idx <= model.MonthIndex(q.To); idx++ {
+				month := model.MonthFromIndex(idx)
+				if sub.ActiveMonths(month, month) > 0 {
+					key.month = idx
+					add(key, monthly)
+				}
+			}
+		}
+	}
+
+	res := make([]model.SumRow, 0, len(order))
+	for _, key := range order {
+		row := model.SumRow{
+			ServiceName: key.serviceName,
+			UserID:      key.userID,
+			Category:    key.category,
+			Tag:         key.tag,
+			Currency:    key.currency,
+			Cost:        costs[key],
+		}
+		if key.month >= 0 {
+			row.Month = model.MonthFromIndex(key.month)
+		}
+		if key.planID != 0 {
+			planID := key.planID
+			row.PlanID = &planID
+		}
+		res = append(res, row)
+	}
+	return res, nil
+}
@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"subscription/internal/config"
+	"subscription/internal/repository/sqlstore"
+	"subscription/internal/service"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Storage — хранилище подписок в MySQL 8+. Запросы — общие из sqlstore.
+type Storage struct {
+	*sqlstore.Storage
+}
+
+func NewMySQLDB(cfg *config.Config) (*Storage, error) {
+
+	mc := mysql.NewConfig()
+	mc.User = cfg.Database.User
+	mc.Passwd = cfg.Database.Password
+	mc.Net = "tcp"
+	mc.Addr = net.JoinHostPort(cfg.Database.Host, cfg.Database.Port)
+	mc.DBName = cfg.Database.Name
+	// DATE читаем сразу в time.Time
+	mc.ParseTime = true
+	// RowsAffected считает найденные, а не изменённые строки: иначе UPDATE
+	// с теми же значениями неотличим от обновления несуществующей записи
+	mc.ClientFoundRows = true
+
+	db, err := sql.Open("mysql", mc.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("sql.Open: %w", err)
+	}
+
+	if cfg.Database.Pool != nil {
+		db.SetConnMaxLifetime(cfg.Database.Pool.ConnLifetime)
+		db.SetMaxOpenConns(cfg.Database.Pool.MaxOpenConns)
+		db.SetMaxIdleConns(cfg.Database.Pool.MaxIdleConns)
+	}
+
+	s, err := sqlstore.Connect(db, sqlstore.Dialect{
+		MapError:     mapError,
+		LockClause:   " FOR UPDATE",
+		InsertIgnore: "INSERT IGNORE",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Storage{Storage: s}, nil
+}
+
+// Коды ошибок MySQL.
+const (
+	codeSignal   = 1644 // SIGNAL SQLSTATE '45000' из триггеров
+	codeDupEntry = 1062 // нарушение уникального ключа
+)
+
+// mapError распознаёт ошибки MySQL: триггеры сообщают о пересечении через
+// SIGNAL с текстом 'subscriptions_no_overlap', дубль уникального ключа — ErrDuplicate.
+func mapError(err error) error {
+	var myErr *mysql.MySQLError
+	switch {
+	case errors.As(err, &myErr) && myErr.Number == codeSignal && myErr.Message == "subscriptions_no_overlap":
+		return &service.OverlapError{}
+	case errors.As(err, &myErr) && myErr.Number == codeDupEntry:
+		return fmt.Errorf("%w: %w", service.ErrDuplicate, err)
+	case errors.Is(err, mysql.ErrInvalidConn):
+		return service.Unavailable(err)
+	}
+	return nil
+}
+
+// проверяем имплиментацию
+var _ service.SubscriptionRepository = (*Storage)(nil)
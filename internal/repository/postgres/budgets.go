@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+func (s *Storage) CreateBudget(ctx context.Context, b model.Budget) (model.Budget, error) {
+	query := `
+        INSERT INTO budgets (user_id, category, amount, currency)
+        VALUES ($1, $2, $3, $4) RETURNING id
+    `
+	err := s.db.QueryRow(ctx, query, b.UserID, b.Category, b.Amount, b.Currency).Scan(&b.ID)
+	return b, mapError(err)
+}
+
+func (s *Storage) GetBudget(ctx context.Context, id int) (model.Budget, error) {
+	var b model.Budget
+	err := s.db.QueryRow(ctx, `SELECT id, user_id, category, amount, currency FROM budgets WHERE id = $1`, id).
+		Scan(&b.ID, &b.UserID, &b.Category, &b.Amount, &b.Currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Budget{}, service.ErrNotFound
+	}
+	return b, mapError(err)
+}
+
+func (s *Storage) ListBudgets(ctx context.Context, userID string) ([]model.Budget, error) {
+	query := `SELECT id, user_id, category, amount, currency FROM budgets`
+	var args []interface{}
+	if userID != "" {
+		query += ` WHERE user_id = $1`
+		args = append(args, userID)
+	}
+	rows, err := s.db.Query(ctx, query+` ORDER BY user_id, category`, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	res, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.Budget, error) {
+		var b model.Budget
+		err := row.Scan(&b.ID, &b.UserID, &b.Category, &b.Amount, &b.Currency)
+		return b, err
+	})
+	if err != nil {
+		return nil, mapError(fmt.Errorf("rows: %w", err))
+	}
+	return res, nil
+}
+
+func (s *Storage) UpdateBudget(ctx context.Context, b model.Budget) (model.Budget, error) {
+	query := `
+        UPDATE budgets SET category = $1, amount = $2, currency = $3
+        WHERE id = $4
+        RETURNING user_id
+    `
+	err := s.db.QueryRow(ctx, query, b.Category, b.Amount, b.Currency, b.ID).Scan(&b.UserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Budget{}, service.ErrNotFound
+	}
+	return b, mapError(err)
+}
+
+func (s *Storage) DeleteBudget(ctx context.Context, id int) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM budgets WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Storage) AddBudgetAlert(ctx context.Context, alert model.BudgetAlert) (model.BudgetAlert, bool, error) {
+	query := `
+        INSERT INTO budget_alerts (budget_id, user_id, category, month, threshold, spent, amount, currency, subscription_id, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        ON CONFLICT (budget_id, month, threshold) DO NOTHING
+        RETURNING id
+    `
+	err := s.db.QueryRow(ctx, query, alert.BudgetID, alert.UserID, alert.Category, alert.Month, alert.Threshold,
+		alert.Spent, alert.Amount, alert.Currency, alert.SubscriptionID, alert.CreatedAt).Scan(&alert.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return alert, false, nil
+	}
+	if err != nil {
+		return alert, false, mapError(err)
+	}
+	return alert, true, nil
+}
+
+func (s *Storage) ListBudgetAlerts(ctx context.Context, budgetID int) ([]model.BudgetAlert, error) {
+	query := `
+        SELECT id, budget_id, user_id, category, month, threshold, spent, amount, currency, subscription_id, created_at
+        FROM budget_alerts
+        WHERE budget_id = $1
+        ORDER BY id
+    `
+	rows, err := s.db.Query(ctx, query, budgetID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	res, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.BudgetAlert, error) {
+		var a model.BudgetAlert
+		err := row.Scan(&a.ID, &a.BudgetID, &a.UserID, &a.Category, &a.Month, &a.Threshold,
+			&a.Spent, &a.Amount, &a.Currency, &a.SubscriptionID, &a.CreatedAt)
+		return a, err
+	})
+	if err != nil {
+		return nil, mapError(fmt.Errorf("rows: %w", err))
+	}
+	return res, nil
+}
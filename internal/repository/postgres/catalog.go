@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+// CreateService добавляет сервис с псевдонимами. Вызывается внутри InTx.
+func (s *Storage) CreateService(ctx context.Context, svc model.Service) (model.Service, error) {
+	query := `
+        INSERT INTO services (name, name_key, category, vendor_url)
+        VALUES ($1, $2, $3, $4) RETURNING id
+    `
+	err := s.db.QueryRow(ctx, query, svc.Name, model.ServiceKey(svc.Name), svc.Category, svc.VendorURL).Scan(&svc.ID)
+	if err != nil {
+		return svc, mapError(err)
+	}
+	return svc, s.insertAliases(ctx, svc)
+}
+
+func (s *Storage) GetService(ctx context.Context, id int) (model.Service, error) {
+	services, err := s.listServices(ctx, "WHERE id = $1", id)
+	if err != nil {
+		return model.Service{}, err
+	}
+	if len(services) == 0 {
+		return model.Service{}, service.ErrNotFound
+	}
+	return services[0], nil
+}
+
+func (s *Storage) FindServiceByKey(ctx context.Context, key string) (model.Service, error) {
+	query := `
+        SELECT id FROM services WHERE name_key = $1
+        UNION ALL
+        SELECT service_id FROM service_aliases WHERE alias_key = $1
+        LIMIT 1
+    `
+	var id int
+	err := s.db.QueryRow(ctx, query, key).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Service{}, service.ErrNotFound
+	}
+	if err != nil {
+		return model.Service{}, mapError(err)
+	}
+	return s.GetService(ctx, id)
+}
+
+func (s *Storage) ListServices(ctx context.Context) ([]model.Service, error) {
+	return s.listServices(ctx, "")
+}
+
+// UpdateService перезаписывает сервис и заменяет псевдонимы. Вызывается внутри InTx.
+func (s *Storage) UpdateService(ctx context.Context, svc model.Service) (model.Service, error) {
+	query := `
+        UPDATE services SET name = $1, name_key = $2, category = $3, vendor_url = $4
+        WHERE id = $5
+    `
+	tag, err := s.db.Exec(ctx, query, svc.Name, model.ServiceKey(svc.Name), svc.Category, svc.VendorURL, svc.ID)
+	if err != nil {
+		return svc, mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return svc, service.ErrNotFound
+	}
+	if _, err := s.db.Exec(ctx, `DELETE FROM service_aliases WHERE service_id = $1`, svc.ID); err != nil {
+		return svc, mapError(err)
+	}
+	return svc, s.insertAliases(ctx, svc)
+}
+
+func (s *Storage) DeleteService(ctx context.Context, id int) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM services WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Storage) ListServiceSubscriptionIDs(ctx context.Context, name string) ([]int, error) {
+	rows, err := s.db.Query(ctx, `SELECT id FROM subscriptions WHERE service_name = $1 AND deleted_at IS NULL ORDER BY id`, name)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, mapError(fmt.Errorf("rows: %w", err))
+	}
+	return ids, nil
+}
+
+func (s *Storage) insertAliases(ctx context.Context, svc model.Service) error {
+	var batch pgx.Batch
+	for _, alias := range svc.Aliases {
+		batch.Queue(`INSERT INTO service_aliases (alias_key, alias, service_id) VALUES ($1, $2, $3)`,
+			model.ServiceKey(alias), alias, svc.ID)
+	}
+	return s.execBatch(ctx, &batch)
+}
+
+// listServices читает сервисы по условию where и подтягивает их псевдонимы вторым запросом.
+func (s *Storage) listServices(ctx context.Context, where string, args ...interface{}) ([]model.Service, error) {
+	rows, err := s.db.Query(ctx, `SELECT id, name, category, vendor_url FROM services `+where+` ORDER BY name, id`, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	var res []model.Service
+	ids := make([]int, 0)
+	for rows.Next() {
+		svc := model.Service{Aliases: []string{}}
+		if err := rows.Scan(&svc.ID, &svc.Name, &svc.Category, &svc.VendorURL); err != nil {
+			return nil, mapError(err)
+		}
+		res = append(res, svc)
+		ids = append(ids, svc.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(err)
+	}
+	if len(res) == 0 {
+		return res, nil
+	}
+
+	aliasRows, err := s.db.Query(ctx, `
+        SELECT service_id, alias FROM service_aliases
+        WHERE service_id = ANY($1)
+        ORDER BY alias
+    `, ids)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer aliasRows.Close()
+
+	byID := make(map[int]*model.Service, len(res))
+	for i := range res {
+		byID[res[i].ID] = &res[i]
+	}
+	for aliasRows.Next() {
+		var id int
+		var alias string
+		if err := aliasRows.Scan(&id, &alias); err != nil {
+			return nil, mapError(err)
+		}
+		byID[id].Aliases = append(byID[id].Aliases, alias)
+	}
+	return res, mapError(aliasRows.Err())
+}
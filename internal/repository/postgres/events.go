@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"subscription/internal/model"
+)
+
+func (s *Storage) AddSubscriptionEvent(ctx context.Context, ev model.SubscriptionEvent) error {
+	query := `
+        INSERT INTO subscription_events (subscription_id, event_type, actor, request_id, before_state, after_state, created_at)
+        VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7)
+    `
+	_, err := s.db.Exec(ctx, query, ev.SubscriptionID, string(ev.Type), ev.Actor, ev.RequestID,
+		[]byte(ev.Before), []byte(ev.After), ev.CreatedAt)
+	return mapError(err)
+}
+
+func (s *Storage) ListSubscriptionEvents(ctx context.Context, subscriptionID int) ([]model.SubscriptionEvent, error) {
+	query := `
+        SELECT id, subscription_id, event_type, COALESCE(actor, ''), COALESCE(request_id, ''), before_state, after_state, created_at
+        FROM subscription_events
+        WHERE subscription_id = $1
+        ORDER BY id
+    `
+	rows, err := s.db.Query(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	var res []model.SubscriptionEvent
+	for rows.Next() {
+		var ev model.SubscriptionEvent
+		var before, after []byte
+		if err := rows.Scan(&ev.ID, &ev.SubscriptionID, &ev.Type, &ev.Actor, &ev.RequestID, &before, &after, &ev.CreatedAt); err != nil {
+			return nil, mapError(err)
+		}
+		ev.Before, ev.After = before, after
+		res = append(res, ev)
+	}
+	return res, mapError(rows.Err())
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"subscription/internal/model"
+	"subscription/internal/service"
+	"time"
+)
+
+// SetFXRates записывает курсы; курс той же пары с той же даты заменяется.
+func (s *Storage) SetFXRates(ctx context.Context, rates []model.FXRate) error {
+	query := `
+        INSERT INTO fx_rates (base, quote, valid_from, rate)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (base, quote, valid_from) DO UPDATE SET rate = EXCLUDED.rate
+    `
+	var batch pgx.Batch
+	for _, r := range rates {
+		batch.Queue(query, r.Base, r.Quote, r.ValidFrom, r.Rate)
+	}
+	return s.execBatch(ctx, &batch)
+}
+
+func (s *Storage) ListFXRates(ctx context.Context) ([]model.FXRate, error) {
+	rows, err := s.db.Query(ctx, `SELECT base, quote, valid_from, rate FROM fx_rates ORDER BY base, quote, valid_from`)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	var res []model.FXRate
+	for rows.Next() {
+		var r model.FXRate
+		if err := rows.Scan(&r.Base, &r.Quote, &r.ValidFrom, &r.Rate); err != nil {
+			return nil, mapError(err)
+		}
+		res = append(res, r)
+	}
+	return res, mapError(rows.Err())
+}
+
+func (s *Storage) DeleteFXRate(ctx context.Context, base, quote string, validFrom time.Time) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM fx_rates WHERE base = $1 AND quote = $2 AND valid_from = $3`,
+		base, quote, validFrom)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"subscription/internal/model"
+	"subscription/internal/service"
+	"time"
+)
+
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) (model.IdempotencyRecord, bool, error) {
+	// Истёкший ключ, который ещё не убрал sweeper, можно занять заново.
+	_, err := s.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND expires_at <= $2`,
+		rec.Key, rec.CreatedAt)
+	if err != nil {
+		return rec, false, mapError(err)
+	}
+
+	query := `
+        INSERT INTO idempotency_keys (idempotency_key, request_hash, created_at, expires_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (idempotency_key) DO NOTHING
+    `
+	tag, err := s.db.Exec(ctx, query, rec.Key, rec.RequestHash, rec.CreatedAt, rec.ExpiresAt)
+	if err != nil {
+		return rec, false, mapError(err)
+	}
+	if tag.RowsAffected() == 1 {
+		return rec, true, nil
+	}
+
+	existing, err := s.getIdempotencyRecord(ctx, rec.Key)
+	return existing, false, err
+}
+
+func (s *Storage) getIdempotencyRecord(ctx context.Context, key string) (model.IdempotencyRecord, error) {
+	query := `
+        SELECT idempotency_key, request_hash, status_code, response_headers, response_body, created_at, expires_at
+        FROM idempotency_keys
+        WHERE idempotency_key = $1
+    `
+	var rec model.IdempotencyRecord
+	var status *int
+	var header *string
+	var body []byte
+	err := s.db.QueryRow(ctx, query, key).
+		Scan(&rec.Key, &rec.RequestHash, &status, &header, &body, &rec.CreatedAt, &rec.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Ключ удалили между INSERT и SELECT: первый запрос сорвался, пусть клиент повторит.
+		return rec, service.ErrIdempotencyInProgress
+	}
+	if err != nil {
+		return rec, mapError(err)
+	}
+	if status == nil {
+		return rec, nil
+	}
+
+	rec.Response = &model.IdempotentResponse{StatusCode: *status, Body: body}
+	if header != nil {
+		if err := json.Unmarshal([]byte(*header), &rec.Response.Header); err != nil {
+			return rec, fmt.Errorf("decode response headers: %w", err)
+		}
+	}
+	return rec, nil
+}
+
+func (s *Storage) SaveIdempotentResponse(ctx context.Context, key string, resp model.IdempotentResponse) error {
+	header, err := json.Marshal(resp.Header)
+	if err != nil {
+		return fmt.Errorf("encode response headers: %w", err)
+	}
+	query := `
+        UPDATE idempotency_keys
+        SET status_code = $1, response_headers = $2, response_body = $3
+        WHERE idempotency_key = $4
+    `
+	tag, err := s.db.Exec(ctx, query, resp.StatusCode, string(header), resp.Body, key)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteIdempotencyKey снимает только незавершённый резерв: сохранённый ответ живёт до истечения TTL.
+func (s *Storage) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND status_code IS NULL`, key)
+	return mapError(err)
+}
+
+func (s *Storage) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, mapError(err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// проверяем имплиментацию
+var _ service.IdempotencyRepository = (*Storage)(nil)
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+func (s *Storage) CreatePlan(ctx context.Context, plan model.Plan) (model.Plan, error) {
+	query := `
+        INSERT INTO plans (service_id, name, price, currency)
+        VALUES ($1, $2, $3, $4) RETURNING id
+    `
+	err := s.db.QueryRow(ctx, query, plan.ServiceID, plan.Name, plan.Price, plan.Currency).Scan(&plan.ID)
+	return plan, mapError(err)
+}
+
+func (s *Storage) GetPlan(ctx context.Context, id int) (model.Plan, error) {
+	var plan model.Plan
+	err := s.db.QueryRow(ctx, `SELECT id, service_id, name, price, currency FROM plans WHERE id = $1`, id).
+		Scan(&plan.ID, &plan.ServiceID, &plan.Name, &plan.Price, &plan.Currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Plan{}, service.ErrNotFound
+	}
+	return plan, mapError(err)
+}
+
+func (s *Storage) ListPlans(ctx context.Context, serviceID int) ([]model.Plan, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT id, service_id, name, price, currency FROM plans
+        WHERE service_id = $1
+        ORDER BY name, id
+    `, serviceID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	var res []model.Plan
+	for rows.Next() {
+		var plan model.Plan
+		if err := rows.Scan(&plan.ID, &plan.ServiceID, &plan.Name, &plan.Price, &plan.Currency); err != nil {
+			return nil, mapError(err)
+		}
+		res = append(res, plan)
+	}
+	return res, mapError(rows.Err())
+}
+
+func (s *Storage) UpdatePlan(ctx context.Context, plan model.Plan) (model.Plan, error) {
+	query := `
+        UPDATE plans SET name = $1, price = $2, currency = $3
+        WHERE id = $4
+        RETURNING service_id
+    `
+	err := s.db.QueryRow(ctx, query, plan.Name, plan.Price, plan.Currency, plan.ID).Scan(&plan.ServiceID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Plan{}, service.ErrNotFound
+	}
+	return plan, mapError(err)
+}
+
+func (s *Storage) DeletePlan(ctx context.Context, id int) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM plans WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
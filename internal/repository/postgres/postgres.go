@@ -2,18 +2,50 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"strings"
 	"subscription/internal/config"
 	"subscription/internal/model"
+	"subscription/internal/repository/sqlsum"
+	"subscription/internal/service"
 	"time"
 )
 
+// sortColumns — белый список колонок для ORDER BY: имя колонки подставляется в SQL напрямую.
+var sortColumns = map[model.SortField]string{
+	model.SortByID:          "id",
+	model.SortByPrice:       "price",
+	model.SortByStartDate:   "start_date",
+	model.SortByServiceName: "service_name",
+}
+
+// Storage работает с Postgres напрямую через пул pgx, без database/sql: меньше аллокаций
+// на запрос, кэш подготовленных выражений и батчи — многострочные записи (курсы валют,
+// псевдонимы сервисов) уходят одним обменом с сервером, см. execBatch.
 type Storage struct {
-	db *sql.DB
+	pool *pgxpool.Pool
+	db   querier
+}
+
+// querier — общее у пула и транзакции: методы Storage работают с любым из них.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// execBatch отправляет выражения батча одним обменом с сервером и возвращает первую ошибку.
+// Вне транзакции выражения батча выполняются в неявной транзакции: ошибка откатывает все.
+func (s *Storage) execBatch(ctx context.Context, b *pgx.Batch) error {
+	if b.Len() == 0 {
+		return nil
+	}
+	return mapError(s.db.SendBatch(ctx, b).Close())
 }
 
 func NewPostgresDB(cfg *config.Config) (*Storage, error) {
@@ -21,27 +53,34 @@ func NewPostgresDB(cfg *config.Config) (*Storage, error) {
 	ps := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s",
 		cfg.Database.Host, cfg.Database.Port, cfg.Database.Name, cfg.Database.User, cfg.Database.Password, cfg.Database.SSLMode)
 
-	db, err := sql.Open("pgx", ps)
+	pcfg, err := pgxpool.ParseConfig(ps)
 	if err != nil {
-		return nil, fmt.Errorf("sql.Open: %w", err)
+		return nil, fmt.Errorf("pgxpool.ParseConfig: %w", err)
 	}
 
-	// Устанавливаем таймауты
-	// ConnLifetime задаётся в секундах в конфиге
-	db.SetConnMaxLifetime(cfg.Database.Pool.ConnLifetime)
-	db.SetMaxOpenConns(cfg.Database.Pool.MaxOpenConns)
-	db.SetMaxIdleConns(cfg.Database.Pool.MaxIdleConns)
+	// Запросы готовятся один раз на соединение и дальше берутся из кэша.
+	pcfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	if p := cfg.Database.Pool; p != nil {
+		pcfg.MaxConns = int32(p.MaxOpenConns)
+		pcfg.MinConns = int32(p.MinConns)
+		pcfg.MaxConnLifetime = p.ConnLifetime
+		pcfg.MaxConnIdleTime = p.MaxConnIdleTime
+		pcfg.HealthCheckPeriod = p.HealthCheckPeriod
+		pcfg.ConnConfig.StatementCacheCapacity = p.StatementCacheCapacity
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	s := &Storage{db: db}
-	if err := s.Ping(ctx); err != nil {
-		// Не забываем закрыть открытое соединение
-		if cerr := db.Close(); cerr != nil {
-			return nil, errors.Join(err, cerr)
-		}
+	pool, err := pgxpool.NewWithConfig(ctx, pcfg)
+	if err != nil {
+		return nil, fmt.Errorf("pgxpool.NewWithConfig: %w", err)
+	}
 
+	s := &Storage{pool: pool, db: pool}
+	if err := s.Ping(ctx); err != nil {
+		// Не забываем закрыть открытый пул
+		pool.Close()
 		return nil, err
 	}
 
@@ -55,136 +94,217 @@ func (s *Storage) Ping(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
 	defer cancel()
 
-	// Используем PingContext вместо Ping
-	if err := s.db.PingContext(ctx); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+	if err := s.pool.Ping(ctx); err != nil {
+		return service.Unavailable(fmt.Errorf("failed to ping database: %w", err))
 	}
 	return nil
 }
 
+// CreateSubscription добавляет подписку вместе с метками. Вызывается внутри InTx.
 func (s *Storage) CreateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error) {
 	query := `
-        INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-        VALUES ($1, $2, $3, $4, $5) RETURNING id
+        INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date, billing_unit, billing_count, currency,
+                                   trial_unit, trial_count, intro_price, intro_periods, plan_id, category)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, version
     `
-	return sub, s.db.QueryRowContext(ctx, query, sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate).
-		Scan(&sub.ID)
+	args := append([]interface{}{sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate,
+		string(sub.BillingPeriod.Unit), sub.BillingPeriod.Count, sub.Currency}, newPromoColumns(sub).args()...)
+	args = append(args, sub.PlanID, sub.Category)
+	if err := s.db.QueryRow(ctx, query, args...).Scan(&sub.ID, &sub.Version); err != nil {
+		return sub, mapError(err)
+	}
+	return sub, s.setTags(ctx, sub.ID, sub.Tags)
+}
+
+func (s *Storage) GetSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error) {
+	return s.getSubscription(ctx, id, includeDeleted, "")
+}
+
+// LockSubscription читает подписку с блокировкой строки до конца транзакции.
+func (s *Storage) LockSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error) {
+	return s.getSubscription(ctx, id, includeDeleted, " FOR UPDATE")
 }
 
-func (s *Storage) GetSubscription(ctx context.Context, id int) (model.Subscription, error) {
+func (s *Storage) getSubscription(ctx context.Context, id int, includeDeleted bool, lock string) (model.Subscription, error) {
 	query := `
-        SELECT id, service_name, price, user_id, start_date, end_date
+        SELECT id, service_name, price, user_id, start_date, end_date, version, deleted_at, billing_unit, billing_count, currency,
+               trial_unit, trial_count, intro_price, intro_periods, plan_id, category, ` + tagsColumn + `
         FROM subscriptions
         WHERE id = $1
     `
-	var sub model.Subscription
-	var endDate sql.NullTime
-	row := s.db.QueryRowContext(ctx, query, id)
-	err := row.Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &endDate)
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+	sub, err := scanSubscription(s.db.QueryRow(ctx, query+lock, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Subscription{}, service.ErrNotFound
+	}
 	if err != nil {
-		return sub, err
+		return model.Subscription{}, mapError(err)
 	}
-	if endDate.Valid {
-		sub.EndDate = &endDate.Time
+	return *sub, nil
+}
+
+// InTx выполняет fn в транзакции; вложенный вызов переиспользует уже открытую.
+func (s *Storage) InTx(ctx context.Context, fn func(repo service.SubscriptionRepository) error) error {
+	if _, ok := s.db.(pgx.Tx); ok {
+		return fn(s)
 	}
-	return sub, nil
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(&Storage{pool: s.pool, db: tx})
+	})
+	return mapError(err)
 }
 
-// ListSubscriptions Архитектурно правильно было бы делать пагинацию
-func (s *Storage) ListSubscriptions(ctx context.Context, userID, serviceName string) ([]*model.Subscription, error) {
-	var where []string
-	var args []interface{}
-	idx := 1
+// ListSubscriptions возвращает не больше page.Limit подписок, идущих после курсора page.After.
+// Пагинация keyset: (поле сортировки, id) сравнивается с позицией курсора, поэтому глубина
+// страницы не влияет на стоимость запроса.
+func (s *Storage) ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Page) ([]*model.Subscription, error) {
+	where, args := filterConditions(filter)
+	idx := len(args) + 1
 
-	if userID != "" {
-		where = append(where, fmt.Sprintf("user_id = $%d", idx))
-		args = append(args, userID)
-		idx++
+	column, ok := sortColumns[page.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field: %q", page.Sort)
 	}
-	if serviceName != "" {
-		where = append(where, fmt.Sprintf("service_name = $%d", idx))
-		args = append(args, serviceName)
-		idx++
+	cmp, dir := ">", "ASC"
+	if page.Desc {
+		cmp, dir = "<", "DESC"
+	}
+
+	if page.After != nil {
+		if page.Sort == model.SortByID {
+			where = append(where, fmt.Sprintf("id %s $%d", cmp, idx))
+			args = append(args, page.After.ID)
+			idx++
+		} else {
+			value, err := page.Sort.ParseValue(page.After.Value)
+			if err != nil {
+				return nil, fmt.Errorf("cursor value: %w", err)
+			}
+			where = append(where, fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, cmp, idx, idx+1))
+			args = append(args, value, page.After.ID)
+			idx += 2
+		}
 	}
 
 	query := `
-        SELECT id, service_name, price, user_id, start_date, end_date
+        SELECT id, service_name, price, user_id, start_date, end_date, version, deleted_at, billing_unit, billing_count, currency,
+               trial_unit, trial_count, intro_price, intro_periods, plan_id, category, ` + tagsColumn + `
         FROM subscriptions
     `
 	if len(where) > 0 {
 		query += " WHERE " + strings.Join(where, " AND ")
 	}
-	query += " ORDER BY id"
-
-	rows, err := s.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
+	if page.Sort == model.SortByID {
+		query += fmt.Sprintf(" ORDER BY id %s", dir)
+	} else {
+		query += fmt.Sprintf(" ORDER BY %s %s, id %s", column, dir, dir)
 	}
+	query += fmt.Sprintf(" LIMIT $%d", idx)
+	args = append(args, page.Limit)
 
-	// Будем аккумулировать ошибку закрытия в именованном ретёрне.
-	var retErr error
-	defer func() {
-		if cerr := rows.Close(); cerr != nil {
-			retErr = errors.Join(retErr, fmt.Errorf("rows.Close: %w", cerr))
-		}
-	}()
-
-	var subs []*model.Subscription
-	for rows.Next() {
-		var s model.Subscription
-		var endDate sql.NullTime
+	return s.querySubscriptions(ctx, query, args...)
+}
 
-		if err := rows.Scan(&s.ID, &s.ServiceName, &s.Price, &s.UserID, &s.StartDate, &endDate); err != nil {
-			return nil, fmt.Errorf("scan: %w", err)
-		}
-		if endDate.Valid {
-			s.EndDate = &endDate.Time
-		}
-		subs = append(subs, &s)
+// UpdateSubscription перезаписывает подписку вместе с метками и возвращает её состояние после обновления.
+// Если задан sub.Version, строка обновляется только при совпадении версии. Вызывается внутри InTx.
+func (s *Storage) UpdateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error) {
+	query := `
+        UPDATE subscriptions
+        SET service_name = $1, price = $2, user_id = $3, start_date = $4, end_date = $5,
+            billing_unit = $6, billing_count = $7, currency = $8,
+            trial_unit = $9, trial_count = $10, intro_price = $11, intro_periods = $12, plan_id = $13, category = $14, version = version + 1
+        WHERE id = $15 AND deleted_at IS NULL
+    `
+	args := []interface{}{sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate,
+		string(sub.BillingPeriod.Unit), sub.BillingPeriod.Count, sub.Currency}
+	args = append(append(args, newPromoColumns(sub).args()...), sub.PlanID, sub.Category, sub.ID)
+	if sub.Version != 0 {
+		query += " AND version = $16"
+		args = append(args, sub.Version)
 	}
+	query += " RETURNING id, service_name, price, user_id, start_date, end_date, version, deleted_at, billing_unit, billing_count, currency," +
+		" trial_unit, trial_count, intro_price, intro_periods, plan_id, category, " + tagsColumn
 
-	// Обязательная проверка ошибок итератора (в т.ч. контекстных).
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows: %w", err)
+	updated, err := scanSubscription(s.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Subscription{}, s.missingOrStale(ctx, sub.ID, sub.Version)
+	}
+	if err != nil {
+		return model.Subscription{}, mapError(err)
 	}
+	// RETURNING видит метки до обновления.
+	if err := s.setTags(ctx, sub.ID, sub.Tags); err != nil {
+		return model.Subscription{}, err
+	}
+	updated.Tags = sub.Tags
+	return *updated, nil
+}
 
-	// Если была только ошибка Close — вернём её отдельно.
-	return subs, retErr
+// DeleteSubscription мягко удаляет подписку; version, если не 0, должна совпасть с текущей.
+func (s *Storage) DeleteSubscription(ctx context.Context, id, version int) error {
+	query := `UPDATE subscriptions SET deleted_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL`
+	args := []interface{}{id}
+	if version != 0 {
+		query += " AND version = $2"
+		args = append(args, version)
+	}
+	tag, err := s.db.Exec(ctx, query, args...)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return s.missingOrStale(ctx, id, version)
+	}
+	return nil
 }
 
-func (s *Storage) UpdateSubscription(ctx context.Context, sub model.Subscription) error {
+// RestoreSubscription снимает отметку об удалении.
+func (s *Storage) RestoreSubscription(ctx context.Context, id int) (model.Subscription, error) {
 	query := `
         UPDATE subscriptions
-        SET service_name = $1, price = $2, user_id = $3, start_date = $4, end_date = $5
-        WHERE id = $6
-    `
-	_, err := s.db.ExecContext(ctx, query, sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate, sub.ID)
-	return err
+        SET deleted_at = NULL, version = version + 1
+        WHERE id = $1 AND deleted_at IS NOT NULL
+        RETURNING id, service_name, price, user_id, start_date, end_date, version, deleted_at, billing_unit, billing_count, currency,
+               trial_unit, trial_count, intro_price, intro_periods, plan_id, category, ` + tagsColumn
+	sub, err := scanSubscription(s.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Subscription{}, service.ErrNotFound
+	}
+	if err != nil {
+		return model.Subscription{}, mapError(err)
+	}
+	return *sub, nil
 }
 
-func (s *Storage) DeleteSubscription(ctx context.Context, id int) error {
-	query := `DELETE FROM subscriptions WHERE id = $1`
-	_, err := s.db.ExecContext(ctx, query, id)
-	return err
+// PurgeDeletedSubscriptions окончательно удаляет подписки, удалённые не позже before.
+func (s *Storage) PurgeDeletedSubscriptions(ctx context.Context, before time.Time) (int, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM subscriptions WHERE deleted_at <= $1`, before)
+	if err != nil {
+		return 0, mapError(err)
+	}
+	return int(tag.RowsAffected()), nil
 }
 
-func (s *Storage) Sum(ctx context.Context, userID, serviceName string, startPeriod, endPeriod time.Time) (int, error) {
-	var where []string
-	var args []interface{}
-	idx := 1
-
-	// Фильтр по user_id
-	if userID != "" {
-		where = append(where, fmt.Sprintf("user_id = $%d", idx))
-		args = append(args, userID)
-		idx++
+// missingOrStale объясняет, почему UPDATE/DELETE не затронул строку: её нет
+// или версия уже другая.
+func (s *Storage) missingOrStale(ctx context.Context, id, version int) error {
+	if version == 0 {
+		return service.ErrNotFound
 	}
-	// Фильтр по service_name
-	if serviceName != "" {
-		where = append(where, fmt.Sprintf("service_name = $%d", idx))
-		args = append(args, serviceName)
-		idx++
+	if _, err := s.GetSubscription(ctx, id, false); err != nil {
+		return err
 	}
+	return service.ErrPreconditionFailed
+}
+
+// ListActiveSubscriptions возвращает подписки, активные хотя бы один месяц внутри периода.
+// Саму стоимость считает сервис: ему нужны даты каждой подписки, а не только цена.
+func (s *Storage) ListActiveSubscriptions(ctx context.Context, filter model.SubscriptionFilter, startPeriod, endPeriod time.Time) ([]*model.Subscription, error) {
+	where, args := filterConditions(filter)
+	idx := len(args) + 1
+
 	// Подписка считается активной, если ее интервал пересекает выбранный период
 	// Учитываем только подписки, у которых start_date <= endPeriod и (end_date IS NULL OR end_date >= startPeriod)
 	where = append(where, fmt.Sprintf("start_date <= $%d", idx))
@@ -192,19 +312,175 @@ func (s *Storage) Sum(ctx context.Context, userID, serviceName string, startPeri
 	idx++
 	where = append(where, fmt.Sprintf("(end_date IS NULL OR end_date >= $%d)", idx))
 	args = append(args, startPeriod)
-	idx++
 
-	// Если подписок не будет, вернем просто 0
-	query := "SELECT COALESCE(SUM(price), 0) FROM subscriptions"
-	if len(where) > 0 {
-		query += " WHERE " + strings.Join(where, " AND ")
+	query := `
+        SELECT id, service_name, price, user_id, start_date, end_date, version, deleted_at, billing_unit, billing_count, currency,
+               trial_unit, trial_count, intro_price, intro_periods, plan_id, category, ` + tagsColumn + `
+        FROM subscriptions
+    `
+	query += " WHERE " + strings.Join(where, " AND ")
+	query += " ORDER BY id"
+
+	return s.querySubscriptions(ctx, query, args...)
+}
+
+// filterConditions переводит фильтр в условия WHERE. Плейсхолдеры нумеруются с $1,
+// следующий свободный номер — len(args)+1.
+func filterConditions(f model.SubscriptionFilter) ([]string, []interface{}) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
 	}
 
-	var sum int
-	err := s.db.QueryRowContext(ctx, query, args...).Scan(&sum)
-	return sum, err
+	if len(f.UserIDs) > 0 {
+		add("user_id = ANY($%d)", f.UserIDs)
+	}
+	if len(f.ServiceNames) > 0 {
+		switch f.ServiceNameMatch {
+		case model.MatchIgnoreCase:
+			names := make([]string, len(f.ServiceNames))
+			for i, n := range f.ServiceNames {
+				names[i] = strings.ToLower(n)
+			}
+			add("LOWER(service_name) = ANY($%d)", names)
+		case model.MatchPrefix:
+			patterns := make([]string, len(f.ServiceNames))
+			for i, n := range f.ServiceNames {
+				patterns[i] = likeEscaper.Replace(n) + "%"
+			}
+			add("service_name ILIKE ANY($%d)", patterns)
+		default:
+			add("service_name = ANY($%d)", f.ServiceNames)
+		}
+	}
+	if len(f.Categories) > 0 {
+		add("category = ANY($%d)", f.Categories)
+	}
+	if len(f.Tags) > 0 {
+		add("EXISTS (SELECT 1 FROM subscription_tags t WHERE t.subscription_id = subscriptions.id AND t.tag = ANY($%d))", f.Tags)
+	}
+	if f.PriceMin != nil {
+		add("price >= $%d", *f.PriceMin)
+	}
+	if f.PriceMax != nil {
+		add("price <= $%d", *f.PriceMax)
+	}
+	if f.ActiveWithin != nil {
+		add("start_date <= $%d", f.ActiveWithin.To)
+		add("(end_date IS NULL OR end_date >= $%d)", f.ActiveWithin.From)
+	}
+	if f.HasEndDate != nil {
+		if *f.HasEndDate {
+			where = append(where, "end_date IS NOT NULL")
+		} else {
+			where = append(where, "end_date IS NULL")
+		}
+	}
+	if f.Adjusted != nil {
+		if *f.Adjusted {
+			where = append(where, sqlsum.AdjustedCondition)
+		} else {
+			where = append(where, "NOT "+sqlsum.AdjustedCondition)
+		}
+	}
+	if !f.IncludeDeleted {
+		where = append(where, "deleted_at IS NULL")
+	}
+	return where, args
+}
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы пользовательский ввод искался буквально.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (s *Storage) FindOverlappingSubscription(ctx context.Context, sub model.Subscription) (int, error) {
+	// Та же логика, что у ограничения subscriptions_no_overlap: границы включительно, NULL — бессрочно,
+	// удалённые подписки не учитываются. Название сравнивается по ключу сервиса, чтобы поймать и дубли
+	// под другим написанием, оставшиеся от миграции 014_services.
+	query := `
+        SELECT id
+        FROM subscriptions
+        WHERE user_id = $1 AND (service_name = $2 OR LOWER(TRIM(service_name)) = $3) AND id <> $4 AND deleted_at IS NULL
+          AND daterange(start_date, end_date, '[]') && daterange($5::date, $6::date, '[]')
+        ORDER BY id
+        LIMIT 1
+    `
+	var id int
+	err := s.db.QueryRow(ctx, query, sub.UserID, sub.ServiceName, model.ServiceKey(sub.ServiceName), sub.ID, sub.StartDate, sub.EndDate).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return id, mapError(err)
+}
+
+// querySubscriptions выполняет SELECT по подпискам и сканирует результат.
+func (s *Storage) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]*model.Subscription, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	subs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Subscription, error) {
+		return scanSubscription(row)
+	})
+	if err != nil {
+		return nil, mapError(fmt.Errorf("rows: %w", err))
+	}
+	return subs, nil
+}
+
+// scanSubscription читает строку в порядке колонок
+// id, service_name, price, user_id, start_date, end_date, version, deleted_at, billing_unit, billing_count, currency,
+// trial_unit, trial_count, intro_price, intro_periods, plan_id, category, tags.
+func scanSubscription(row pgx.Row) (*model.Subscription, error) {
+	var sub model.Subscription
+	var promo promoColumns
+	dest := append([]interface{}{&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate, &sub.Version, &sub.DeletedAt,
+		&sub.BillingPeriod.Unit, &sub.BillingPeriod.Count, &sub.Currency}, promo.dest()...)
+	dest = append(dest, &sub.PlanID, &sub.Category, &sub.Tags)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	promo.apply(&sub)
+	if len(sub.Tags) == 0 {
+		sub.Tags = nil
+	}
+	return &sub, nil
 }
 
 func (s *Storage) Close() error {
-	return s.db.Close()
+	s.pool.Close()
+	return nil
+}
+
+// SQLSTATE нарушений ограничений.
+const (
+	codeExclusionViolation = "23P01"
+	codeUniqueViolation    = "23505"
+)
+
+// mapError переводит ошибки Postgres в ошибки сервисного слоя: нарушение ограничений —
+// в конфликт, обрыв соединения и таймауты — в ErrUnavailable.
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case codeExclusionViolation:
+			return &service.OverlapError{}
+		case codeUniqueViolation:
+			return fmt.Errorf("%w: %w", service.ErrDuplicate, err)
+		}
+		return err
+	}
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) || pgconn.Timeout(err) {
+		return service.Unavailable(err)
+	}
+	return err
 }
+
+// проверяем имплиментацию
+var _ service.SubscriptionRepository = (*Storage)(nil)
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"subscription/internal/model"
+)
+
+// SetPriceChange записывает изменение цены; изменение с того же месяца заменяется.
+func (s *Storage) SetPriceChange(ctx context.Context, ch model.PriceChange) error {
+	query := `
+        INSERT INTO subscription_prices (subscription_id, effective_from, price)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price
+    `
+	_, err := s.db.Exec(ctx, query, ch.SubscriptionID, ch.EffectiveFrom, ch.Price)
+	return mapError(err)
+}
+
+func (s *Storage) ListPriceChanges(ctx context.Context, subscriptionIDs []int) ([]model.PriceChange, error) {
+	if len(subscriptionIDs) == 0 {
+		return nil, nil
+	}
+	query := `
+        SELECT subscription_id, effective_from, price
+        FROM subscription_prices
+        WHERE subscription_id = ANY($1)
+        ORDER BY subscription_id, effective_from
+    `
+	rows, err := s.db.Query(ctx, query, subscriptionIDs)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	var res []model.PriceChange
+	for rows.Next() {
+		var ch model.PriceChange
+		if err := rows.Scan(&ch.SubscriptionID, &ch.EffectiveFrom, &ch.Price); err != nil {
+			return nil, mapError(err)
+		}
+		res = append(res, ch)
+	}
+	return res, mapError(rows.Err())
+}
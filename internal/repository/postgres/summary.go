@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"subscription/internal/model"
+	"subscription/internal/repository/sqlsum"
+)
+
+// sumDialect — выражения PostgreSQL для запроса суммы.
+var sumDialect = sqlsum.Dialect{
+	MonthIndex: func(column string) string {
+		return fmt.Sprintf("(EXTRACT(YEAR FROM %[1]s) * 12 + EXTRACT(MONTH FROM %[1]s) - 1)::int", column)
+	},
+	Real:    "::float8",
+	NullInt: "NULL::int",
+}
+
+// SumSubscriptions считает стоимость подписок без поправок одним запросом (см. sqlsum.Dialect.Query).
+func (s *Storage) SumSubscriptions(ctx context.Context, q model.SumQuery) ([]model.SumRow, error) {
+	where, args := filterConditions(sqlsum.Filter(q))
+	rows, err := s.db.Query(ctx, sumDialect.Query(q, where), args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	res, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.SumRow, error) {
+		var r model.SumRow
+		month := -1
+		if err := row.Scan(&r.Currency, &month, &r.ServiceName, &r.UserID, &r.PlanID, &r.Category, &r.Tag, &r.Cost); err != nil {
+			return r, err
+		}
+		if month >= 0 {
+			r.Month = model.MonthFromIndex(month)
+		}
+		return r, nil
+	})
+	if err != nil {
+		return nil, mapError(fmt.Errorf("rows: %w", err))
+	}
+	return res, nil
+}
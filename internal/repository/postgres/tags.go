@@ -0,0 +1,18 @@
+package postgres
+
+import "context"
+
+// tagsColumn — метки подписки массивом, упорядоченным по метке; ставится последней колонкой SELECT.
+const tagsColumn = "ARRAY(SELECT tag FROM subscription_tags t WHERE t.subscription_id = subscriptions.id ORDER BY tag)"
+
+// setTags заменяет метки подписки. Вызывается внутри InTx.
+func (s *Storage) setTags(ctx context.Context, id int, tags []string) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM subscription_tags WHERE subscription_id = $1`, id); err != nil {
+		return mapError(err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := s.db.Exec(ctx, `INSERT INTO subscription_tags (subscription_id, tag) SELECT $1, unnest($2::text[])`, id, tags)
+	return mapError(err)
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"subscription/internal/config"
+	"subscription/internal/repository/sqlstore"
+	"subscription/internal/service"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Storage — хранилище подписок в файле SQLite. Драйвер modernc.org/sqlite написан на чистом Go,
+// поэтому сборка остаётся с CGO_ENABLED=0. Запросы — общие из sqlstore.
+type Storage struct {
+	*sqlstore.Storage
+}
+
+func NewSQLiteDB(cfg *config.Config) (*Storage, error) {
+
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)",
+		cfg.Database.Path)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sql.Open: %w", err)
+	}
+
+	// SQLite допускает одного писателя: одно соединение избавляет от SQLITE_BUSY
+	// при параллельных запросах, для небольших инсталляций этого достаточно.
+	db.SetMaxOpenConns(1)
+
+	// FOR UPDATE в SQLite нет: транзакция и так держит единственное соединение.
+	s, err := sqlstore.Connect(db, sqlstore.Dialect{MapError: mapError, InsertIgnore: "INSERT OR IGNORE"})
+	if err != nil {
+		return nil, err
+	}
+	return &Storage{Storage: s}, nil
+}
+
+// mapError распознаёт ошибки SQLite: триггеры сообщают о пересечении через
+// RAISE(ABORT, 'subscriptions_no_overlap'), дубль уникального ключа — ErrDuplicate,
+// занятая другим процессом БД — SQLITE_BUSY.
+func mapError(err error) error {
+	var sqliteErr *sqlite.Error
+	switch {
+	case strings.Contains(err.Error(), "subscriptions_no_overlap"):
+		return &service.OverlapError{}
+	case errors.As(err, &sqliteErr) && (sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE ||
+		sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY):
+		return fmt.Errorf("%w: %w", service.ErrDuplicate, err)
+	case errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY:
+		return service.Unavailable(err)
+	}
+	return nil
+}
+
+// проверяем имплиментацию
+var _ service.SubscriptionRepository = (*Storage)(nil)
@@ -0,0 +1,149 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+func (s *Storage) CreateBudget(ctx context.Context, b model.Budget) (model.Budget, error) {
+	res, err := s.q.ExecContext(ctx, `INSERT INTO budgets (user_id, category, amount, currency) VALUES (?, ?, ?, ?)`,
+		b.UserID, b.Category, b.Amount, b.Currency)
+	if err != nil {
+		return b, s.mapError(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return b, fmt.Errorf("last insert id: %w", err)
+	}
+	b.ID = int(id)
+	return b, nil
+}
+
+func (s *Storage) GetBudget(ctx context.Context, id int) (model.Budget, error) {
+	var b model.Budget
+	err := s.q.QueryRowContext(ctx, `SELECT id, user_id, category, amount, currency FROM budgets WHERE id = ?`, id).
+		Scan(&b.ID, &b.UserID, &b.Category, &b.Amount, &b.Currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Budget{}, service.ErrNotFound
+	}
+	return b, s.mapError(err)
+}
+
+func (s *Storage) ListBudgets(ctx context.Context, userID string) (res []model.Budget, retErr error) {
+	query := `SELECT id, user_id, category, amount, currency FROM budgets`
+	var args []interface{}
+	if userID != "" {
+		query += ` WHERE user_id = ?`
+		args = append(args, userID)
+	}
+	rows, err := s.q.QueryContext(ctx, query+` ORDER BY user_id, category`, args...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("rows.Close: %w", cerr))
+		}
+	}()
+
+	for rows.Next() {
+		var b model.Budget
+		if err := rows.Scan(&b.ID, &b.UserID, &b.Category, &b.Amount, &b.Currency); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		res = append(res, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(fmt.Errorf("rows: %w", err))
+	}
+	return res, nil
+}
+
+// UpdateBudget перезаписывает бюджет. Наличие проверяется отдельным запросом, как в UpdatePlan.
+func (s *Storage) UpdateBudget(ctx context.Context, b model.Budget) (model.Budget, error) {
+	current, err := s.GetBudget(ctx, b.ID)
+	if err != nil {
+		return model.Budget{}, err
+	}
+	_, err = s.q.ExecContext(ctx, `UPDATE budgets SET category = ?, amount = ?, currency = ? WHERE id = ?`,
+		b.Category, b.Amount, b.Currency, b.ID)
+	if err != nil {
+		return model.Budget{}, s.mapError(err)
+	}
+	b.UserID = current.UserID
+	return b, nil
+}
+
+func (s *Storage) DeleteBudget(ctx context.Context, id int) error {
+	res, err := s.q.ExecContext(ctx, `DELETE FROM budgets WHERE id = ?`, id)
+	if err != nil {
+		return s.mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Storage) AddBudgetAlert(ctx context.Context, alert model.BudgetAlert) (model.BudgetAlert, bool, error) {
+	query := s.dialect.InsertIgnore + ` INTO budget_alerts (budget_id, user_id, category, month, threshold, spent, amount, currency, subscription_id, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+	res, err := s.q.ExecContext(ctx, query, alert.BudgetID, alert.UserID, alert.Category, date(alert.Month), alert.Threshold,
+		alert.Spent, alert.Amount, alert.Currency, alert.SubscriptionID, timestamp(alert.CreatedAt))
+	if err != nil {
+		return alert, false, s.mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return alert, false, fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return alert, false, nil
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return alert, false, fmt.Errorf("last insert id: %w", err)
+	}
+	alert.ID = int(id)
+	return alert, true, nil
+}
+
+func (s *Storage) ListBudgetAlerts(ctx context.Context, budgetID int) (res []model.BudgetAlert, retErr error) {
+	query := `
+        SELECT id, budget_id, user_id, category, month, threshold, spent, amount, currency, subscription_id, created_at
+        FROM budget_alerts
+        WHERE budget_id = ?
+        ORDER BY id
+    `
+	rows, err := s.q.QueryContext(ctx, query, budgetID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("rows.Close: %w", cerr))
+		}
+	}()
+
+	for rows.Next() {
+		var a model.BudgetAlert
+		if err := rows.Scan(&a.ID, &a.BudgetID, &a.UserID, &a.Category, &a.Month, &a.Threshold,
+			&a.Spent, &a.Amount, &a.Currency, &a.SubscriptionID, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		res = append(res, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(fmt.Errorf("rows: %w", err))
+	}
+	return res, nil
+}
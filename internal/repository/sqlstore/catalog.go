@@ -0,0 +1,185 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+// CreateService добавляет сервис с псевдонимами. Вызывается внутри InTx.
+func (s *Storage) CreateService(ctx context.Context, svc model.Service) (model.Service, error) {
+	res, err := s.q.ExecContext(ctx, `INSERT INTO services (name, name_key, category, vendor_url) VALUES (?, ?, ?, ?)`,
+		svc.Name, model.ServiceKey(svc.Name), svc.Category, svc.VendorURL)
+	if err != nil {
+		return svc, s.mapError(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return svc, fmt.Errorf("last insert id: %w", err)
+	}
+	svc.ID = int(id)
+	return svc, s.insertAliases(ctx, svc)
+}
+
+func (s *Storage) GetService(ctx context.Context, id int) (model.Service, error) {
+	services, err := s.listServices(ctx, "WHERE id = ?", id)
+	if err != nil {
+		return model.Service{}, err
+	}
+	if len(services) == 0 {
+		return model.Service{}, service.ErrNotFound
+	}
+	return services[0], nil
+}
+
+func (s *Storage) FindServiceByKey(ctx context.Context, key string) (model.Service, error) {
+	query := `
+        SELECT id FROM services WHERE name_key = ?
+        UNION ALL
+        SELECT service_id FROM service_aliases WHERE alias_key = ?
+        LIMIT 1
+    `
+	var id int
+	err := s.q.QueryRowContext(ctx, query, key, key).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Service{}, service.ErrNotFound
+	}
+	if err != nil {
+		return model.Service{}, s.mapError(err)
+	}
+	return s.GetService(ctx, id)
+}
+
+func (s *Storage) ListServices(ctx context.Context) ([]model.Service, error) {
+	return s.listServices(ctx, "")
+}
+
+// UpdateService перезаписывает сервис и заменяет псевдонимы. Вызывается внутри InTx.
+// Наличие проверяется отдельным запросом: MySQL не считает строку, если значения не изменились.
+func (s *Storage) UpdateService(ctx context.Context, svc model.Service) (model.Service, error) {
+	if _, err := s.GetService(ctx, svc.ID); err != nil {
+		return svc, err
+	}
+	_, err := s.q.ExecContext(ctx, `UPDATE services SET name = ?, name_key = ?, category = ?, vendor_url = ? WHERE id = ?`,
+		svc.Name, model.ServiceKey(svc.Name), svc.Category, svc.VendorURL, svc.ID)
+	if err != nil {
+		return svc, s.mapError(err)
+	}
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM service_aliases WHERE service_id = ?`, svc.ID); err != nil {
+		return svc, s.mapError(err)
+	}
+	return svc, s.insertAliases(ctx, svc)
+}
+
+// DeleteService удаляет сервис; псевдонимы удаляет каскад по внешнему ключу.
+func (s *Storage) DeleteService(ctx context.Context, id int) error {
+	res, err := s.q.ExecContext(ctx, `DELETE FROM services WHERE id = ?`, id)
+	if err != nil {
+		return s.mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Storage) ListServiceSubscriptionIDs(ctx context.Context, name string) (ids []int, retErr error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id FROM subscriptions WHERE service_name = ? AND deleted_at IS NULL ORDER BY id`, name)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("rows.Close: %w", cerr))
+		}
+	}()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, s.mapError(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(err)
+	}
+	return ids, nil
+}
+
+func (s *Storage) insertAliases(ctx context.Context, svc model.Service) error {
+	for _, alias := range svc.Aliases {
+		_, err := s.q.ExecContext(ctx, `INSERT INTO service_aliases (alias_key, alias, service_id) VALUES (?, ?, ?)`,
+			model.ServiceKey(alias), alias, svc.ID)
+		if err != nil {
+			return s.mapError(err)
+		}
+	}
+	return nil
+}
+
+// listServices читает сервисы по условию where и подтягивает их псевдонимы вторым запросом.
+func (s *Storage) listServices(ctx context.Context, where string, args ...interface{}) (res []model.Service, retErr error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, name, category, vendor_url FROM services `+where+` ORDER BY name, id`, args...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("rows.Close: %w", cerr))
+		}
+	}()
+
+	var ids []interface{}
+	for rows.Next() {
+		svc := model.Service{Aliases: []string{}}
+		if err := rows.Scan(&svc.ID, &svc.Name, &svc.Category, &svc.VendorURL); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		res = append(res, svc)
+		ids = append(ids, svc.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(fmt.Errorf("rows: %w", err))
+	}
+	if len(res) == 0 {
+		return res, nil
+	}
+	// Соединение одно на SQLite: первый результат закрываем до второго запроса.
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("rows.Close: %w", err)
+	}
+
+	aliasRows, err := s.q.QueryContext(ctx, `
+        SELECT service_id, alias FROM service_aliases
+        WHERE service_id IN (`+placeholders(len(ids))+`)
+        ORDER BY alias
+    `, ids...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer aliasRows.Close()
+
+	byID := make(map[int]*model.Service, len(res))
+	for i := range res {
+		byID[res[i].ID] = &res[i]
+	}
+	for aliasRows.Next() {
+		var id int
+		var alias string
+		if err := aliasRows.Scan(&id, &alias); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		byID[id].Aliases = append(byID[id].Aliases, alias)
+	}
+	if err := aliasRows.Err(); err != nil {
+		return nil, s.mapError(fmt.Errorf("rows: %w", err))
+	}
+	return res, nil
+}
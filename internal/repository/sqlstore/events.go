@@ -0,0 +1,57 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"subscription/internal/model"
+)
+
+func (s *Storage) AddSubscriptionEvent(ctx context.Context, ev model.SubscriptionEvent) error {
+	query := `
+        INSERT INTO subscription_events (subscription_id, event_type, actor, request_id, before_state, after_state, created_at)
+        VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?)
+    `
+	_, err := s.q.ExecContext(ctx, query, ev.SubscriptionID, string(ev.Type), ev.Actor, ev.RequestID,
+		jsonState(ev.Before), jsonState(ev.After), timestamp(ev.CreatedAt))
+	return s.mapError(err)
+}
+
+func (s *Storage) ListSubscriptionEvents(ctx context.Context, subscriptionID int) ([]model.SubscriptionEvent, error) {
+	query := `
+        SELECT id, subscription_id, event_type, COALESCE(actor, ''), COALESCE(request_id, ''), before_state, after_state, created_at
+        FROM subscription_events
+        WHERE subscription_id = ?
+        ORDER BY id
+    `
+	rows, err := s.q.QueryContext(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer rows.Close()
+
+	var res []model.SubscriptionEvent
+	for rows.Next() {
+		var ev model.SubscriptionEvent
+		var before, after sql.NullString
+		if err := rows.Scan(&ev.ID, &ev.SubscriptionID, &ev.Type, &ev.Actor, &ev.RequestID, &before, &after, &ev.CreatedAt); err != nil {
+			return nil, s.mapError(err)
+		}
+		if before.Valid {
+			ev.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			ev.After = json.RawMessage(after.String)
+		}
+		res = append(res, ev)
+	}
+	return res, s.mapError(rows.Err())
+}
+
+// jsonState передаёт состояние подписки строкой, пустое — как NULL.
+func jsonState(raw json.RawMessage) any {
+	if raw == nil {
+		return nil
+	}
+	return string(raw)
+}
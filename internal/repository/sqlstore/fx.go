@@ -0,0 +1,61 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"subscription/internal/model"
+	"subscription/internal/service"
+	"time"
+)
+
+// SetFXRates записывает курсы; курс той же пары с той же даты заменяется.
+// Вызывается внутри InTx, поэтому удаление и вставка видны другим только вместе.
+func (s *Storage) SetFXRates(ctx context.Context, rates []model.FXRate) error {
+	for _, r := range rates {
+		_, err := s.q.ExecContext(ctx, `DELETE FROM fx_rates WHERE base = ? AND quote = ? AND valid_from = ?`,
+			r.Base, r.Quote, date(r.ValidFrom))
+		if err != nil {
+			return s.mapError(err)
+		}
+		_, err = s.q.ExecContext(ctx, `INSERT INTO fx_rates (base, quote, valid_from, rate) VALUES (?, ?, ?, ?)`,
+			r.Base, r.Quote, date(r.ValidFrom), r.Rate)
+		if err != nil {
+			return s.mapError(err)
+		}
+	}
+	return nil
+}
+
+func (s *Storage) ListFXRates(ctx context.Context) ([]model.FXRate, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT base, quote, valid_from, rate FROM fx_rates ORDER BY base, quote, valid_from`)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer rows.Close()
+
+	var res []model.FXRate
+	for rows.Next() {
+		var r model.FXRate
+		if err := rows.Scan(&r.Base, &r.Quote, &r.ValidFrom, &r.Rate); err != nil {
+			return nil, s.mapError(err)
+		}
+		res = append(res, r)
+	}
+	return res, s.mapError(rows.Err())
+}
+
+func (s *Storage) DeleteFXRate(ctx context.Context, base, quote string, validFrom time.Time) error {
+	res, err := s.q.ExecContext(ctx, `DELETE FROM fx_rates WHERE base = ? AND quote = ? AND valid_from = ?`,
+		base, quote, date(validFrom))
+	if err != nil {
+		return s.mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"subscription/internal/model"
+	"subscription/internal/service"
+	"time"
+)
+
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) (model.IdempotencyRecord, bool, error) {
+	// Истёкший ключ, который ещё не убрал sweeper, можно занять заново.
+	_, err := s.q.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE idempotency_key = ? AND expires_at <= ?`,
+		rec.Key, timestamp(rec.CreatedAt))
+	if err != nil {
+		return rec, false, s.mapError(err)
+	}
+
+	query := s.dialect.InsertIgnore + ` INTO idempotency_keys (idempotency_key, request_hash, created_at, expires_at)
+        VALUES (?, ?, ?, ?)
+    `
+	res, err := s.q.ExecContext(ctx, query, rec.Key, rec.RequestHash, timestamp(rec.CreatedAt), timestamp(rec.ExpiresAt))
+	if err != nil {
+		return rec, false, s.mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return rec, false, fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 1 {
+		return rec, true, nil
+	}
+
+	existing, err := s.getIdempotencyRecord(ctx, rec.Key)
+	return existing, false, err
+}
+
+func (s *Storage) getIdempotencyRecord(ctx context.Context, key string) (model.IdempotencyRecord, error) {
+	query := `
+        SELECT idempotency_key, request_hash, status_code, response_headers, response_body, created_at, expires_at
+        FROM idempotency_keys
+        WHERE idempotency_key = ?
+    `
+	var rec model.IdempotencyRecord
+	var status sql.NullInt64
+	var header sql.NullString
+	var body []byte
+	err := s.q.QueryRowContext(ctx, query, key).
+		Scan(&rec.Key, &rec.RequestHash, &status, &header, &body, &rec.CreatedAt, &rec.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Ключ удалили между INSERT и SELECT: первый запрос сорвался, пусть клиент повторит.
+		return rec, service.ErrIdempotencyInProgress
+	}
+	if err != nil {
+		return rec, s.mapError(err)
+	}
+	if !status.Valid {
+		return rec, nil
+	}
+
+	rec.Response = &model.IdempotentResponse{StatusCode: int(status.Int64), Body: body}
+	if header.Valid {
+		if err := json.Unmarshal([]byte(header.String), &rec.Response.Header); err != nil {
+			return rec, fmt.Errorf("decode response headers: %w", err)
+		}
+	}
+	return rec, nil
+}
+
+func (s *Storage) SaveIdempotentResponse(ctx context.Context, key string, resp model.IdempotentResponse) error {
+	header, err := json.Marshal(resp.Header)
+	if err != nil {
+		return fmt.Errorf("encode response headers: %w", err)
+	}
+	query := `
+        UPDATE idempotency_keys
+        SET status_code = ?, response_headers = ?, response_body = ?
+        WHERE idempotency_key = ?
+    `
+	res, err := s.q.ExecContext(ctx, query, resp.StatusCode, string(header), resp.Body, key)
+	if err != nil {
+		return s.mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteIdempotencyKey снимает только незавершённый резерв: сохранённый ответ живёт до истечения TTL.
+func (s *Storage) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE idempotency_key = ? AND status_code IS NULL`, key)
+	return s.mapError(err)
+}
+
+func (s *Storage) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int, error) {
+	res, err := s.q.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= ?`, timestamp(now))
+	if err != nil {
+		return 0, s.mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+	return int(n), nil
+}
+
+// проверяем имплиментацию
+var _ service.IdempotencyRepository = (*Storage)(nil)
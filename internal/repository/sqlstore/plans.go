@@ -0,0 +1,93 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"subscription/internal/model"
+	"subscription/internal/service"
+)
+
+func (s *Storage) CreatePlan(ctx context.Context, plan model.Plan) (model.Plan, error) {
+	res, err := s.q.ExecContext(ctx, `INSERT INTO plans (service_id, name, price, currency) VALUES (?, ?, ?, ?)`,
+		plan.ServiceID, plan.Name, plan.Price, plan.Currency)
+	if err != nil {
+		return plan, s.mapError(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return plan, fmt.Errorf("last insert id: %w", err)
+	}
+	plan.ID = int(id)
+	return plan, nil
+}
+
+func (s *Storage) GetPlan(ctx context.Context, id int) (model.Plan, error) {
+	var plan model.Plan
+	err := s.q.QueryRowContext(ctx, `SELECT id, service_id, name, price, currency FROM plans WHERE id = ?`, id).
+		Scan(&plan.ID, &plan.ServiceID, &plan.Name, &plan.Price, &plan.Currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Plan{}, service.ErrNotFound
+	}
+	return plan, s.mapError(err)
+}
+
+func (s *Storage) ListPlans(ctx context.Context, serviceID int) (res []model.Plan, retErr error) {
+	rows, err := s.q.QueryContext(ctx, `
+        SELECT id, service_id, name, price, currency FROM plans
+        WHERE service_id = ?
+        ORDER BY name, id
+    `, serviceID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("rows.Close: %w", cerr))
+		}
+	}()
+
+	for rows.Next() {
+		var plan model.Plan
+		if err := rows.Scan(&plan.ID, &plan.ServiceID, &plan.Name, &plan.Price, &plan.Currency); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		res = append(res, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(fmt.Errorf("rows: %w", err))
+	}
+	return res, nil
+}
+
+// UpdatePlan перезаписывает тариф. Как и в UpdateService, наличие проверяется отдельным
+// запросом: MySQL не считает строку, значения которой не изменились.
+func (s *Storage) UpdatePlan(ctx context.Context, plan model.Plan) (model.Plan, error) {
+	current, err := s.GetPlan(ctx, plan.ID)
+	if err != nil {
+		return model.Plan{}, err
+	}
+	_, err = s.q.ExecContext(ctx, `UPDATE plans SET name = ?, price = ?, currency = ? WHERE id = ?`,
+		plan.Name, plan.Price, plan.Currency, plan.ID)
+	if err != nil {
+		return model.Plan{}, s.mapError(err)
+	}
+	plan.ServiceID = current.ServiceID
+	return plan, nil
+}
+
+func (s *Storage) DeletePlan(ctx context.Context, id int) error {
+	res, err := s.q.ExecContext(ctx, `DELETE FROM plans WHERE id = ?`, id)
+	if err != nil {
+		return s.mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
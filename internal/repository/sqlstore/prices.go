@@ -0,0 +1,50 @@
+package sqlstore
+
+import (
+	"context"
+	"subscription/internal/model"
+)
+
+// SetPriceChange записывает изменение цены; изменение с того же месяца заменяется.
+// Вызывается внутри InTx, поэтому удаление и вставка видны другим только вместе.
+func (s *Storage) SetPriceChange(ctx context.Context, ch model.PriceChange) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM subscription_prices WHERE subscription_id = ? AND effective_from = ?`,
+		ch.SubscriptionID, date(ch.EffectiveFrom))
+	if err != nil {
+		return s.mapError(err)
+	}
+	_, err = s.q.ExecContext(ctx, `INSERT INTO subscription_prices (subscription_id, effective_from, price) VALUES (?, ?, ?)`,
+		ch.SubscriptionID, date(ch.EffectiveFrom), ch.Price)
+	return s.mapError(err)
+}
+
+func (s *Storage) ListPriceChanges(ctx context.Context, subscriptionIDs []int) ([]model.PriceChange, error) {
+	if len(subscriptionIDs) == 0 {
+		return nil, nil
+	}
+	query := `
+        SELECT subscription_id, effective_from, price
+        FROM subscription_prices
+        WHERE subscription_id IN (` + placeholders(len(subscriptionIDs)) + `)
+        ORDER BY subscription_id, effective_from
+    `
+	args := make([]any, len(subscriptionIDs))
+	for i, id := range subscriptionIDs {
+		args[i] = id
+	}
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer rows.Close()
+
+	var res []model.PriceChange
+	for rows.Next() {
+		var ch model.PriceChange
+		if err := rows.Scan(&ch.SubscriptionID, &ch.EffectiveFrom, &ch.Price); err != nil {
+			return nil, s.mapError(err)
+		}
+		res = append(res, ch)
+	}
+	return res, s.mapError(rows.Err())
+}
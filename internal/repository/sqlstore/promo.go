@@ -0,0 +1,44 @@
+package sqlstore
+
+import "subscription/internal/model"
+
+// promoColumns — пробный период и вводная цена в колонках trial_unit, trial_count,
+// intro_price, intro_periods; NULL в trial_unit и intro_price означает, что их нет.
+type promoColumns struct {
+	trialUnit    *string
+	trialCount   int
+	introPrice   *int
+	introPeriods int
+}
+
+func newPromoColumns(sub model.Subscription) promoColumns {
+	var p promoColumns
+	if sub.Trial != nil {
+		unit := string(sub.Trial.Unit)
+		p.trialUnit, p.trialCount = &unit, sub.Trial.Count
+	}
+	if sub.Intro != nil {
+		p.introPrice, p.introPeriods = &sub.Intro.Price, sub.Intro.Periods
+	}
+	return p
+}
+
+// args — значения колонок для INSERT и UPDATE.
+func (p promoColumns) args() []interface{} {
+	return []interface{}{p.trialUnit, p.trialCount, p.introPrice, p.introPeriods}
+}
+
+// dest — получатели для Scan.
+func (p *promoColumns) dest() []interface{} {
+	return []interface{}{&p.trialUnit, &p.trialCount, &p.introPrice, &p.introPeriods}
+}
+
+// apply переносит прочитанные колонки в подписку.
+func (p promoColumns) apply(sub *model.Subscription) {
+	if p.trialUnit != nil {
+		sub.Trial = &model.Trial{Unit: model.TrialUnit(*p.trialUnit), Count: p.trialCount}
+	}
+	if p.introPrice != nil {
+		sub.Intro = &model.IntroPrice{Price: *p.introPrice, Periods: p.introPeriods}
+	}
+}
@@ -0,0 +1,509 @@
+// Package sqlstore — общая реализация хранилища подписок поверх database/sql
+// для SQLite и MySQL. Запросы пишутся на подмножестве SQL, которое понимают оба
+// диалекта: плейсхолдеры «?», даты строками YYYY-MM-DD, без RETURNING.
+// Различия (подключение, ошибки ограничений) задают пакеты sqlite и mysql.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"subscription/internal/model"
+	"subscription/internal/repository/sqlsum"
+	"subscription/internal/service"
+	"time"
+)
+
+// dateLayout — формат, в котором даты передаются в запросы.
+const dateLayout = "2006-01-02"
+
+// timestampLayout — формат времени в запросах: в UTC, с точностью до секунды.
+// Строки в этом формате сравниваются так же, как само время.
+const timestampLayout = "2006-01-02 15:04:05"
+
+// sortColumns — белый список колонок для ORDER BY: имя колонки подставляется в SQL напрямую.
+var sortColumns = map[model.SortField]string{
+	model.SortByID:          "id",
+	model.SortByPrice:       "price",
+	model.SortByStartDate:   "start_date",
+	model.SortByServiceName: "service_name",
+}
+
+// Dialect — то, чем SQLite и MySQL отличаются для общих запросов.
+type Dialect struct {
+	// MapError переводит специфичные для драйвера ошибки (например, срабатывание триггера
+	// пересечения) в ошибки сервисного слоя и возвращает nil для всех остальных.
+	MapError func(error) error
+	// LockClause дописывается к SELECT, чтобы заблокировать строку до конца транзакции.
+	LockClause string
+	// InsertIgnore — начало INSERT, который молча пропускает строку с уже занятым ключом.
+	InsertIgnore string
+}
+
+// dbtx — общее у *sql.DB и *sql.Tx: методы Storage работают с любым из них.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type Storage struct {
+	db      *sql.DB
+	q       dbtx
+	dialect Dialect
+}
+
+// Connect проверяет открытое подключение и оборачивает его в Storage; при ошибке закрывает db.
+func Connect(db *sql.DB, dialect Dialect) (*Storage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s := &Storage{db: db, q: db, dialect: dialect}
+	if err := s.Ping(ctx); err != nil {
+		// Не забываем закрыть открытое соединение
+		if cerr := db.Close(); cerr != nil {
+			return nil, errors.Join(err, cerr)
+		}
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return service.Unavailable(fmt.Errorf("failed to ping database: %w", err))
+	}
+	return nil
+}
+
+// CreateSubscription добавляет подписку вместе с метками. Вызывается внутри InTx.
+func (s *Storage) CreateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error) {
+	query := `
+        INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date, billing_unit, billing_count, currency,
+                                   trial_unit, trial_count, intro_price, intro_periods, plan_id, category)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+	args := append([]interface{}{sub.ServiceName, sub.Price, sub.UserID, date(sub.StartDate), nullDate(sub.EndDate),
+		string(sub.BillingPeriod.Unit), sub.BillingPeriod.Count, sub.Currency}, newPromoColumns(sub).args()...)
+	args = append(args, sub.PlanID, sub.Category)
+	res, err := s.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return sub, s.mapError(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return sub, fmt.Errorf("last insert id: %w", err)
+	}
+	sub.ID = int(id)
+	sub.Version = 1
+	return sub, s.setTags(ctx, sub.ID, sub.Tags)
+}
+
+func (s *Storage) GetSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error) {
+	return s.getSubscription(ctx, id, includeDeleted, "")
+}
+
+// LockSubscription читает подписку с блокировкой строки до конца транзакции.
+func (s *Storage) LockSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error) {
+	return s.getSubscription(ctx, id, includeDeleted, s.dialect.LockClause)
+}
+
+func (s *Storage) getSubscription(ctx context.Context, id int, includeDeleted bool, lock string) (model.Subscription, error) {
+	query := `
+        SELECT id, service_name, price, user_id, start_date, end_date, version, deleted_at, billing_unit, billing_count, currency,
+               trial_unit, trial_count, intro_price, intro_periods, plan_id, category, ` + tagsColumn + `
+        FROM subscriptions
+        WHERE id = ?
+    `
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+	subs, err := s.querySubscriptions(ctx, query+lock, id)
+	if err != nil {
+		return model.Subscription{}, err
+	}
+	if len(subs) == 0 {
+		return model.Subscription{}, service.ErrNotFound
+	}
+	return *subs[0], nil
+}
+
+// InTx выполняет fn в транзакции; вложенный вызов переиспользует уже открытую.
+func (s *Storage) InTx(ctx context.Context, fn func(repo service.SubscriptionRepository) error) error {
+	if _, ok := s.q.(*sql.Tx); ok {
+		return fn(s)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.mapError(fmt.Errorf("begin: %w", err))
+	}
+	if err := fn(&Storage{db: s.db, q: tx, dialect: s.dialect}); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rerr))
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return s.mapError(fmt.Errorf("commit: %w", err))
+	}
+	return nil
+}
+
+// ListSubscriptions — keyset-пагинация, как в postgres.Storage. Сравнение кортежей
+// (col, id) > (?, ?) поддерживают и SQLite (3.15+), и MySQL.
+func (s *Storage) ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Page) ([]*model.Subscription, error) {
+	where, args := filterConditions(filter)
+
+	column, ok := sortColumns[page.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field: %q", page.Sort)
+	}
+	cmp, dir := ">", "ASC"
+	if page.Desc {
+		cmp, dir = "<", "DESC"
+	}
+
+	if page.After != nil {
+		if page.Sort == model.SortByID {
+			where = append(where, fmt.Sprintf("id %s ?", cmp))
+			args = append(args, page.After.ID)
+		} else {
+			value, err := page.Sort.ParseValue(page.After.Value)
+			if err != nil {
+				return nil, fmt.Errorf("cursor value: %w", err)
+			}
+			if t, ok := value.(time.Time); ok {
+				value = date(t)
+			}
+			where = append(where, fmt.Sprintf("(%s, id) %s (?, ?)", column, cmp))
+			args = append(args, value, page.After.ID)
+		}
+	}
+
+	query := `
+        SELECT id, service_name, price, user_id, start_date, end_date, version, deleted_at, billing_unit, billing_count, currency,
+               trial_unit, trial_count, intro_price, intro_periods, plan_id, category, ` + tagsColumn + `
+        FROM subscriptions
+    `
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if page.Sort == model.SortByID {
+		query += fmt.Sprintf(" ORDER BY id %s", dir)
+	} else {
+		query += fmt.Sprintf(" ORDER BY %s %s, id %s", column, dir, dir)
+	}
+	query += " LIMIT ?"
+	args = append(args, page.Limit)
+
+	return s.querySubscriptions(ctx, query, args...)
+}
+
+// UpdateSubscription перезаписывает подписку вместе с метками и возвращает её состояние после обновления.
+// Если задан sub.Version, строка обновляется только при совпадении версии. Вызывается внутри InTx.
+// RETURNING в MySQL нет, поэтому запись перечитывается отдельным запросом.
+func (s *Storage) UpdateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error) {
+	query := `
+        UPDATE subscriptions
+        SET service_name = ?, price = ?, user_id = ?, start_date = ?, end_date = ?,
+            billing_unit = ?, billing_count = ?, currency = ?,
+            trial_unit = ?, trial_count = ?, intro_price = ?, intro_periods = ?, plan_id = ?, category = ?, version = version + 1
+        WHERE id = ? AND deleted_at IS NULL
+    `
+	args := []interface{}{sub.ServiceName, sub.Price, sub.UserID, date(sub.StartDate), nullDate(sub.EndDate),
+		string(sub.BillingPeriod.Unit), sub.BillingPeriod.Count, sub.Currency}
+	args = append(append(args, newPromoColumns(sub).args()...), sub.PlanID, sub.Category, sub.ID)
+	if sub.Version != 0 {
+		query += " AND version = ?"
+		args = append(args, sub.Version)
+	}
+	res, err := s.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return model.Subscription{}, s.mapError(err)
+	}
+	if err := s.checkAffected(ctx, res, sub.ID, sub.Version); err != nil {
+		return model.Subscription{}, err
+	}
+	if err := s.setTags(ctx, sub.ID, sub.Tags); err != nil {
+		return model.Subscription{}, err
+	}
+	return s.GetSubscription(ctx, sub.ID, false)
+}
+
+// DeleteSubscription мягко удаляет подписку; version, если не 0, должна совпасть с текущей.
+func (s *Storage) DeleteSubscription(ctx context.Context, id, version int) error {
+	query := `UPDATE subscriptions SET deleted_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL`
+	args := []interface{}{timestamp(time.Now()), id}
+	if version != 0 {
+		query += " AND version = ?"
+		args = append(args, version)
+	}
+	res, err := s.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return s.mapError(err)
+	}
+	return s.checkAffected(ctx, res, id, version)
+}
+
+// RestoreSubscription снимает отметку об удалении.
+func (s *Storage) RestoreSubscription(ctx context.Context, id int) (model.Subscription, error) {
+	query := `UPDATE subscriptions SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL`
+	res, err := s.q.ExecContext(ctx, query, id)
+	if err != nil {
+		return model.Subscription{}, s.mapError(err)
+	}
+	if err := s.checkAffected(ctx, res, id, 0); err != nil {
+		return model.Subscription{}, err
+	}
+	return s.GetSubscription(ctx, id, false)
+}
+
+// PurgeDeletedSubscriptions окончательно удаляет подписки, удалённые не позже before.
+func (s *Storage) PurgeDeletedSubscriptions(ctx context.Context, before time.Time) (int, error) {
+	res, err := s.q.ExecContext(ctx, `DELETE FROM subscriptions WHERE deleted_at <= ?`, timestamp(before))
+	if err != nil {
+		return 0, s.mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+	return int(n), nil
+}
+
+// checkAffected объясняет, почему запрос не затронул ни одной строки: записи нет (ErrNotFound)
+// или версия уже другая (ErrPreconditionFailed). Для MySQL это работает только с clientFoundRows:
+// иначе UPDATE без изменений даёт 0.
+func (s *Storage) checkAffected(ctx context.Context, res sql.Result, id, version int) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n > 0 {
+		return nil
+	}
+	if version == 0 {
+		return service.ErrNotFound
+	}
+	if _, err := s.GetSubscription(ctx, id, false); err != nil {
+		return err
+	}
+	return service.ErrPreconditionFailed
+}
+
+// ListActiveSubscriptions возвращает подписки, активные хотя бы один месяц внутри периода.
+func (s *Storage) ListActiveSubscriptions(ctx context.Context, filter model.SubscriptionFilter, startPeriod, endPeriod time.Time) ([]*model.Subscription, error) {
+	where, args := filterConditions(filter)
+
+	where = append(where, "start_date <= ?", "(end_date IS NULL OR end_date >= ?)")
+	args = append(args, date(endPeriod), date(startPeriod))
+
+	query := `
+        SELECT id, service_name, price, user_id, start_date, end_date, version, deleted_at, billing_unit, billing_count, currency,
+               trial_unit, trial_count, intro_price, intro_periods, plan_id, category, ` + tagsColumn + `
+        FROM subscriptions
+    `
+	query += " WHERE " + strings.Join(where, " AND ")
+	query += " ORDER BY id"
+
+	return s.querySubscriptions(ctx, query, args...)
+}
+
+func (s *Storage) FindOverlappingSubscription(ctx context.Context, sub model.Subscription) (int, error) {
+	// Та же логика, что у триггеров subscriptions_no_overlap_* в миграциях. Название сравнивается
+	// и по ключу сервиса, чтобы поймать дубли под другим написанием, оставшиеся от миграции 014_services
+	// (LOWER в SQLite понимает только ASCII, поэтому точное совпадение проверяется отдельно).
+	query := `
+        SELECT id
+        FROM subscriptions
+        WHERE user_id = ? AND (service_name = ? OR LOWER(TRIM(service_name)) = ?) AND id <> ? AND deleted_at IS NULL
+          AND start_date <= COALESCE(?, '9999-12-31')
+          AND COALESCE(end_date, '9999-12-31') >= ?
+        ORDER BY id
+        LIMIT 1
+    `
+	var id int
+	err := s.q.QueryRowContext(ctx, query, sub.UserID, sub.ServiceName, model.ServiceKey(sub.ServiceName), sub.ID,
+		nullDate(sub.EndDate), date(sub.StartDate)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, s.mapError(err)
+}
+
+// filterConditions переводит фильтр в условия WHERE с плейсхолдерами «?».
+func filterConditions(f model.SubscriptionFilter) ([]string, []interface{}) {
+	var where []string
+	var args []interface{}
+
+	if len(f.UserIDs) > 0 {
+		where = append(where, "user_id IN ("+placeholders(len(f.UserIDs))+")")
+		for _, id := range f.UserIDs {
+			args = append(args, id)
+		}
+	}
+	if len(f.ServiceNames) > 0 {
+		switch f.ServiceNameMatch {
+		case model.MatchIgnoreCase:
+			where = append(where, "LOWER(service_name) IN ("+placeholders(len(f.ServiceNames))+")")
+			for _, n := range f.ServiceNames {
+				args = append(args, strings.ToLower(n))
+			}
+		case model.MatchPrefix:
+			conds := make([]string, len(f.ServiceNames))
+			for i, n := range f.ServiceNames {
+				conds[i] = "LOWER(service_name) LIKE ? ESCAPE '!'"
+				args = append(args, likeEscaper.Replace(strings.ToLower(n))+"%")
+			}
+			where = append(where, "("+strings.Join(conds, " OR ")+")")
+		default:
+			where = append(where, "service_name IN ("+placeholders(len(f.ServiceNames))+")")
+			for _, n := range f.ServiceNames {
+				args = append(args, n)
+			}
+		}
+	}
+	if len(f.Categories) > 0 {
+		where = append(where, "category IN ("+placeholders(len(f.Categories))+")")
+		for _, c := range f.Categories {
+			args = append(args, c)
+		}
+	}
+	if len(f.Tags) > 0 {
+		where = append(where, "EXISTS (SELECT 1 FROM subscription_tags t WHERE t.subscription_id = subscriptions.id AND t.tag IN ("+placeholders(len(f.Tags))+"))")
+		for _, tag := range f.Tags {
+			args = append(args, tag)
+		}
+	}
+	if f.PriceMin != nil {
+		where = append(where, "price >= ?")
+		args = append(args, *f.PriceMin)
+	}
+	if f.PriceMax != nil {
+		where = append(where, "price <= ?")
+		args = append(args, *f.PriceMax)
+	}
+	if f.ActiveWithin != nil {
+		where = append(where, "start_date <= ?", "(end_date IS NULL OR end_date >= ?)")
+		args = append(args, date(f.ActiveWithin.To), date(f.ActiveWithin.From))
+	}
+	if f.HasEndDate != nil {
+		if *f.HasEndDate {
+			where = append(where, "end_date IS NOT NULL")
+		} else {
+			where = append(where, "end_date IS NULL")
+		}
+	}
+	if f.Adjusted != nil {
+		if *f.Adjusted {
+			where = append(where, sqlsum.AdjustedCondition)
+		} else {
+			where = append(where, "NOT "+sqlsum.AdjustedCondition)
+		}
+	}
+	if !f.IncludeDeleted {
+		where = append(where, "deleted_at IS NULL")
+	}
+	return where, args
+}
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы пользовательский ввод искался буквально.
+// Экранирующий символ «!», а не «\»: обратный слэш в строковых литералах SQLite и MySQL
+// трактуется по-разному.
+var likeEscaper = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// querySubscriptions выполняет SELECT по подпискам и сканирует результат.
+func (s *Storage) querySubscriptions(ctx context.Context, query string, args ...interface{}) (subs []*model.Subscription, retErr error) {
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("rows.Close: %w", cerr))
+		}
+	}()
+
+	for rows.Next() {
+		var s model.Subscription
+		var endDate, deletedAt sql.NullTime
+		var promo promoColumns
+		var tags sql.NullString
+
+		dest := append([]interface{}{&s.ID, &s.ServiceName, &s.Price, &s.UserID, &s.StartDate, &endDate, &s.Version, &deletedAt,
+			&s.BillingPeriod.Unit, &s.BillingPeriod.Count, &s.Currency}, promo.dest()...)
+		dest = append(dest, &s.PlanID, &s.Category, &tags)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		promo.apply(&s)
+		s.Tags = splitTags(tags)
+		if endDate.Valid {
+			s.EndDate = &endDate.Time
+		}
+		if deletedAt.Valid {
+			s.DeletedAt = &deletedAt.Time
+		}
+		subs = append(subs, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(fmt.Errorf("rows: %w", err))
+	}
+
+	return subs, nil
+}
+
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+func date(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+func nullDate(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return date(*t)
+}
+
+// mapError переводит ошибки database/sql в ошибки сервисного слоя: сначала специфичные
+// для драйвера, затем общие признаки недоступности БД.
+func (s *Storage) mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if mapped := s.dialect.MapError(err); mapped != nil {
+		return mapped
+	}
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return service.Unavailable(err)
+	}
+	return err
+}
+
+// проверяем имплиментацию
+var _ service.SubscriptionRepository = (*Storage)(nil)
@@ -0,0 +1,48 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"subscription/internal/model"
+	"subscription/internal/repository/sqlsum"
+)
+
+// sumDialect — выражения запроса суммы, общие для SQLite и MySQL. Даты хранятся строками
+// YYYY-MM-DD, литералы 1e0 делают деление вещественным в обеих базах.
+var sumDialect = sqlsum.Dialect{
+	MonthIndex: func(column string) string {
+		return fmt.Sprintf("(SUBSTR(%[1]s, 1, 4) * 12 + SUBSTR(%[1]s, 6, 2) - 1)", column)
+	},
+	Real:    "e0",
+	NullInt: "NULL",
+}
+
+// SumSubscriptions считает стоимость подписок без поправок одним запросом (см. sqlsum.Dialect.Query).
+func (s *Storage) SumSubscriptions(ctx context.Context, q model.SumQuery) (res []model.SumRow, retErr error) {
+	where, args := filterConditions(sqlsum.Filter(q))
+	rows, err := s.q.QueryContext(ctx, sumDialect.Query(q, where), args...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("rows.Close: %w", cerr))
+		}
+	}()
+	for rows.Next() {
+		var r model.SumRow
+		var month int
+		if err := rows.Scan(&r.Currency, &month, &r.ServiceName, &r.UserID, &r.PlanID, &r.Category, &r.Tag, &r.Cost); err != nil {
+			return nil, s.mapError(err)
+		}
+		if month >= 0 {
+			r.Month = model.MonthFromIndex(month)
+		}
+		res = append(res, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(err)
+	}
+	return res, nil
+}
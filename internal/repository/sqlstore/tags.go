@@ -0,0 +1,35 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+)
+
+// tagsColumn — метки подписки одной строкой через запятую (в метках запятых нет, это проверяет сервис),
+// NULL — меток нет. Порядок GROUP_CONCAT в SQLite не задаётся, поэтому метки сортирует splitTags.
+const tagsColumn = "(SELECT GROUP_CONCAT(tag) FROM subscription_tags t WHERE t.subscription_id = subscriptions.id)"
+
+// setTags заменяет метки подписки. Вызывается внутри InTx.
+func (s *Storage) setTags(ctx context.Context, id int, tags []string) error {
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM subscription_tags WHERE subscription_id = ?`, id); err != nil {
+		return s.mapError(err)
+	}
+	for _, tag := range tags {
+		if _, err := s.q.ExecContext(ctx, `INSERT INTO subscription_tags (subscription_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			return s.mapError(err)
+		}
+	}
+	return nil
+}
+
+// splitTags разбирает колонку tagsColumn.
+func splitTags(v sql.NullString) []string {
+	if !v.Valid || v.String == "" {
+		return nil
+	}
+	tags := strings.Split(v.String, ",")
+	slices.Sort(tags)
+	return tags
+}
@@ -0,0 +1,97 @@
+// Package sqlsum строит запрос SumSubscriptions для SQL-хранилищ. PostgreSQL и sqlstore
+// (SQLite, MySQL) отличаются в нём только выражениями из Dialect, плейсхолдеры условий
+// фильтра и выполнение запроса остаются за хранилищем.
+package sqlsum
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"subscription/internal/model"
+)
+
+// AdjustedCondition — у подписки есть пробный период, вводная цена или график цен.
+const AdjustedCondition = `(trial_unit IS NOT NULL OR intro_price IS NOT NULL
+        OR EXISTS (SELECT 1 FROM subscription_prices p WHERE p.subscription_id = subscriptions.id))`
+
+// Dialect — выражения, которыми хранилища отличаются в запросе суммы.
+type Dialect struct {
+	// MonthIndex — model.MonthIndex для колонки с датой.
+	MonthIndex func(column string) string
+	// Real дописывается к числовому литералу, чтобы деление было вещественным.
+	Real string
+	// NullInt — целочисленный NULL для plan_id без группировки.
+	NullInt string
+}
+
+// Filter — фильтр запроса: подписки без поправок, активные в периоде q.
+func Filter(q model.SumQuery) model.SubscriptionFilter {
+	filter, adjusted := q.Filter, false
+	filter.Adjusted = &adjusted
+	filter.ActiveWithin = &model.Period{From: q.From, To: q.To}
+	return filter
+}
+
+// monthlyShare — доля цены за период списания, приходящаяся на месяц; то же, что model.BillingPeriod.MonthlyShare.
+func (d Dialect) monthlyShare() string {
+	return fmt.Sprintf(`CASE billing_unit
+            WHEN 'week' THEN 365.25%[1]s / 7 / 12 / billing_count
+            WHEN 'quarter' THEN 1%[1]s / (3 * billing_count)
+            WHEN 'year' THEN 1%[1]s / (12 * billing_count)
+            ELSE 1%[1]s / billing_count
+        END`, d.Real)
+}
+
+// Query собирает запрос по условиям where из Filter. Без группировки по месяцу число активных
+// месяцев вычисляется из дат, с ней подписка соединяется с рядом месяцев периода.
+// Индексы месяцев периода — числа, вычисленные здесь же, поэтому подставляются в текст запроса.
+// Колонки результата: currency, индекс месяца (-1 без группировки), service_name, user_id,
+// plan_id, category, tag и стоимость.
+func (d Dialect) Query(q model.SumQuery, where []string) string {
+	from, to := model.MonthIndex(q.From), model.MonthIndex(q.To)
+
+	startIdx := d.MonthIndex("start_date")
+	endIdx := fmt.Sprintf("COALESCE(%s, %d)", d.MonthIndex("end_date"), to)
+	// LEAST и GREATEST есть не везде, поэтому границы обрезаются через CASE.
+	months := fmt.Sprintf("(CASE WHEN %[1]s < %[3]d THEN %[1]s ELSE %[3]d END - CASE WHEN %[2]s > %[4]d THEN %[2]s ELSE %[4]d END + 1)",
+		endIdx, startIdx, to, from)
+	with, source := "", "subscriptions"
+
+	// Измерение без группировки заменяется константой.
+	columns := []string{"-1", "''", "''", d.NullInt, "''", "''"}
+	groupBy := []string{"currency"}
+	group := func(i int, expr string) {
+		columns[i] = expr
+		groupBy = append(groupBy, expr)
+	}
+	if slices.Contains(q.GroupBy, model.GroupByMonth) {
+		with = fmt.Sprintf("WITH RECURSIVE m(idx) AS (SELECT %d UNION ALL SELECT idx + 1 FROM m WHERE idx < %d)", from, to)
+		source += fmt.Sprintf(" JOIN m ON m.idx BETWEEN %s AND %s", startIdx, endIdx)
+		months = "1"
+		group(0, "m.idx")
+	}
+	if slices.Contains(q.GroupBy, model.GroupByServiceName) {
+		group(1, "service_name")
+	}
+	if slices.Contains(q.GroupBy, model.GroupByUserID) {
+		group(2, "user_id")
+	}
+	if slices.Contains(q.GroupBy, model.GroupByPlanID) {
+		group(3, "plan_id")
+	}
+	if slices.Contains(q.GroupBy, model.GroupByCategory) {
+		group(4, "category")
+	}
+	if slices.Contains(q.GroupBy, model.GroupByTag) {
+		source += " LEFT JOIN subscription_tags st ON st.subscription_id = subscriptions.id"
+		group(5, "COALESCE(st.tag, '')")
+	}
+
+	return fmt.Sprintf(`
+        %s
+        SELECT currency, %s, SUM(price * %s * %s)
+        FROM %s
+        WHERE %s
+        GROUP BY %s
+    `, with, strings.Join(columns, ", "), d.monthlyShare(), months, source, strings.Join(where, " AND "), strings.Join(groupBy, ", "))
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"subscription/internal/model"
+	"time"
+)
+
+// AuditInfo — кто и в рамках какого запроса меняет данные; попадает в журнал изменений.
+type AuditInfo struct {
+	Actor     string
+	RequestID string
+}
+
+type auditInfoKey struct{}
+
+// WithAuditInfo кладёт AuditInfo в контекст; его прочитают методы сервиса при записи журнала.
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+func auditInfoFrom(ctx context.Context) AuditInfo {
+	info, _ := ctx.Value(auditInfoKey{}).(AuditInfo)
+	return info
+}
+
+// recordEvent пишет изменение подписки в журнал. Вызывается внутри InTx с тем же repo,
+// что и само изменение, поэтому запись журнала и изменение фиксируются или откатываются вместе.
+// before или after равны nil, если состояния нет (до создания). Обычно это model.Subscription,
+// у price_change — model.PriceChange.
+func recordEvent(ctx context.Context, repo SubscriptionRepository, typ model.EventType, id int, before, after any) error {
+	info := auditInfoFrom(ctx)
+	ev := model.SubscriptionEvent{
+		SubscriptionID: id,
+		Type:           typ,
+		Actor:          info.Actor,
+		RequestID:      info.RequestID,
+		CreatedAt:      time.Now().UTC(),
+	}
+	var err error
+	if ev.Before, err = marshalState(before); err != nil {
+		return err
+	}
+	if ev.After, err = marshalState(after); err != nil {
+		return err
+	}
+	return repo.AddSubscriptionEvent(ctx, ev)
+}
+
+func marshalState(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("marshal subscription state: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"strings"
+	"subscription/internal/model"
+	"time"
+	"unicode/utf8"
+)
+
+// ListBudgets возвращает бюджеты пользователя, а с пустым userID — все.
+func (s *SubscriptionSvc) ListBudgets(ctx context.Context, userID string) (model.BudgetList, error) {
+	budgets, err := s.repo.ListBudgets(ctx, userID)
+	if err != nil {
+		return model.BudgetList{}, err
+	}
+	if budgets == nil {
+		budgets = []model.Budget{}
+	}
+	return model.BudgetList{Items: budgets}, nil
+}
+
+func (s *SubscriptionSvc) GetBudget(ctx context.Context, id int) (model.Budget, error) {
+	return s.repo.GetBudget(ctx, id)
+}
+
+// CreateBudget заводит бюджет; у пользователя один бюджет на категорию и один общий.
+func (s *SubscriptionSvc) CreateBudget(ctx context.Context, b model.Budget) (model.Budget, error) {
+	const op = "internal.service.CreateBudget"
+	log := s.logger.With(slog.String("op", op))
+
+	b = normalizeBudget(b)
+	if err := validateBudget(b); err != nil {
+		return model.Budget{}, err
+	}
+
+	var created model.Budget
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		if err := checkBudgetScope(ctx, repo, b); err != nil {
+			return err
+		}
+		var err error
+		created, err = repo.CreateBudget(ctx, b)
+		return err
+	})
+	if err != nil {
+		log.Error("Can`t create budget", slog.String("error", err.Error()))
+		return model.Budget{}, err
+	}
+	return created, nil
+}
+
+// UpdateBudget меняет категорию, сумму и валюту бюджета; пользователь остаётся прежним.
+// Уже записанные уведомления не пересматриваются.
+func (s *SubscriptionSvc) UpdateBudget(ctx context.Context, b model.Budget) (model.Budget, error) {
+	const op = "internal.service.UpdateBudget"
+	log := s.logger.With(slog.String("op", op))
+
+	b = normalizeBudget(b)
+
+	var updated model.Budget
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		current, err := repo.GetBudget(ctx, b.ID)
+		if err != nil {
+			return err
+		}
+		b.UserID = current.UserID
+		if err := validateBudget(b); err != nil {
+			return err
+		}
+		if err := checkBudgetScope(ctx, repo, b); err != nil {
+			return err
+		}
+		updated, err = repo.UpdateBudget(ctx, b)
+		return err
+	})
+	if err != nil {
+		log.Error("Can`t update budget", slog.String("error", err.Error()))
+		return model.Budget{}, err
+	}
+	return updated, nil
+}
+
+func (s *SubscriptionSvc) DeleteBudget(ctx context.Context, id int) error {
+	return s.repo.DeleteBudget(ctx, id)
+}
+
+// BudgetStatus считает траты за месяц month против бюджета.
+func (s *SubscriptionSvc) BudgetStatus(ctx context.Context, id int, month time.Time) (model.BudgetStatus, error) {
+	b, err := s.repo.GetBudget(ctx, id)
+	if err != nil {
+		return model.BudgetStatus{}, err
+	}
+	spent, err := s.budgetSpent(ctx, b, month)
+	if err != nil {
+		return model.BudgetStatus{}, err
+	}
+	return model.BudgetStatus{
+		Budget:  b,
+		Month:   month.Format(model.MonthLayout),
+		Spent:   spent,
+		Percent: budgetPercent(spent, b.Amount),
+	}, nil
+}
+
+// BudgetAlerts возвращает уведомления бюджета; ErrNotFound, если бюджета нет.
+func (s *SubscriptionSvc) BudgetAlerts(ctx context.Context, id int) (model.BudgetAlertList, error) {
+	if _, err := s.repo.GetBudget(ctx, id); err != nil {
+		return model.BudgetAlertList{}, err
+	}
+	alerts, err := s.repo.ListBudgetAlerts(ctx, id)
+	if err != nil {
+		return model.BudgetAlertList{}, err
+	}
+	if alerts == nil {
+		alerts = []model.BudgetAlert{}
+	}
+	return model.BudgetAlertList{Items: alerts}, nil
+}
+
+// budgetSpent — траты за месяц так же, как их считает Sum: подписки пользователя
+// (и категории бюджета, если она задана) в режиме accrual в валюте бюджета.
+func (s *SubscriptionSvc) budgetSpent(ctx context.Context, b model.Budget, month time.Time) (int, error) {
+	filter := model.SubscriptionFilter{UserIDs: []string{b.UserID}}
+	if b.Category != "" {
+		filter.Categories = []string{b.Category}
+	}
+	summary, err := s.Sum(ctx, filter, month, month, model.SummaryOptions{Mode: model.SumAccrual, Currency: b.Currency})
+	if err != nil {
+		return 0, err
+	}
+	return summary.Total, nil
+}
+
+func budgetPercent(spent, amount int) int {
+	return spent * 100 / amount
+}
+
+// checkBudgets пересчитывает бюджеты, на которые влияет подписка, за первый месяц, начиная
+// с текущего, в котором она активна, и записывает уведомления о достигнутых порогах.
+// Вызывается после того, как запись подписки зафиксирована, поэтому ошибки только логируются.
+func (s *SubscriptionSvc) checkBudgets(ctx context.Context, sub model.Subscription) {
+	const op = "internal.service.checkBudgets"
+	log := s.logger.With(slog.String("op", op), slog.Int("subscription_id", sub.ID))
+
+	now := time.Now().UTC()
+	month := later(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), sub.StartDate)
+	if sub.ActiveMonths(month, month) == 0 {
+		return
+	}
+
+	budgets, err := s.repo.ListBudgets(ctx, sub.UserID)
+	if err != nil {
+		log.Error("Can`t list budgets", slog.String("error", err.Error()))
+		return
+	}
+	for _, b := range budgets {
+		if b.Category != "" && b.Category != sub.Category {
+			continue
+		}
+		spent, err := s.budgetSpent(ctx, b, month)
+		if err != nil {
+			log.Error("Can`t compute budget spend", slog.Int("budget_id", b.ID), slog.String("error", err.Error()))
+			continue
+		}
+		for _, threshold := range model.BudgetThresholds {
+			if budgetPercent(spent, b.Amount) < threshold {
+				break
+			}
+			alert, created, err := s.repo.AddBudgetAlert(ctx, model.BudgetAlert{
+				BudgetID:       b.ID,
+				UserID:         b.UserID,
+				Category:       b.Category,
+				Month:          month,
+				Threshold:      threshold,
+				Spent:          spent,
+				Amount:         b.Amount,
+				Currency:       b.Currency,
+				SubscriptionID: sub.ID,
+				CreatedAt:      now,
+			})
+			if err != nil {
+				log.Error("Can`t add budget alert", slog.Int("budget_id", b.ID), slog.String("error", err.Error()))
+				continue
+			}
+			if created {
+				log.Warn("budget threshold reached", slog.Int("budget_id", b.ID), slog.Int("threshold", threshold))
+				s.sendBudgetAlert(alert)
+			}
+		}
+	}
+}
+
+// sendBudgetAlert отправляет уведомление на budgets.webhook_url в фоне, чтобы не задерживать
+// ответ на запись подписки. Неудачная отправка только логируется, повторов нет:
+// уведомление остаётся в GET /budgets/{id}/alerts. Каждая отправка ограничена
+// budgets.webhook_timeout, при остановке сервиса их дожидается WaitWebhooks.
+func (s *SubscriptionSvc) sendBudgetAlert(alert model.BudgetAlert) {
+	const op = "internal.service.sendBudgetAlert"
+	log := s.logger.With(slog.String("op", op), slog.Int("alert_id", alert.ID))
+
+	url := s.config.Budgets.WebhookURL
+	if url == "" {
+		return
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		log.Error("Can`t encode budget alert", slog.String("error", err.Error()))
+		return
+	}
+
+	s.webhooks.Add(1)
+	go func() {
+		defer s.webhooks.Done()
+
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			log.Error("Can`t build budget webhook request", slog.String("error", err.Error()))
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := s.webhook.Do(req)
+		if err != nil {
+			log.Error("Can`t send budget webhook", slog.String("error", err.Error()))
+			return
+		}
+		if err := resp.Body.Close(); err != nil {
+			log.Warn("Can`t close budget webhook response", slog.String("error", err.Error()))
+		}
+		if resp.StatusCode >= http.StatusMultipleChoices {
+			log.Error("budget webhook rejected", slog.Int("status", resp.StatusCode))
+		}
+	}()
+}
+
+// WaitWebhooks ждёт отправки уведомлений бюджетов, начатые до остановки сервера,
+// но не дольше, чем живёт ctx.
+func (s *SubscriptionSvc) WaitWebhooks(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.webhooks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func normalizeBudget(b model.Budget) model.Budget {
+	b.UserID = strings.TrimSpace(b.UserID)
+	b.Category = strings.ToLower(strings.TrimSpace(b.Category))
+	b.Currency = strings.ToUpper(strings.TrimSpace(b.Currency))
+	if b.Currency == "" {
+		b.Currency = model.DefaultCurrency
+	}
+	return b
+}
+
+func validateBudget(b model.Budget) error {
+	fields := make(map[string]string)
+	if err := uuid.Validate(b.UserID); err != nil {
+		fields["user_id"] = "must be a UUID"
+	}
+	if utf8.RuneCountInString(b.Category) > model.MaxCategoryLength {
+		fields["category"] = fmt.Sprintf("must be at most %d characters", model.MaxCategoryLength)
+	}
+	if b.Amount <= 0 {
+		fields["amount"] = "must be positive"
+	} else {
+		validateAmount(fields, "amount", b.Amount)
+	}
+	if !model.CurrencySupported(b.Currency) {
+		fields["currency"] = "unsupported currency"
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// checkBudgetScope не даёт завести пользователю второй бюджет на ту же категорию.
+func checkBudgetScope(ctx context.Context, repo SubscriptionRepository, b model.Budget) error {
+	budgets, err := repo.ListBudgets(ctx, b.UserID)
+	if err != nil {
+		return err
+	}
+	for _, other := range budgets {
+		if other.ID != b.ID && other.Category == b.Category {
+			return fmt.Errorf("%w: user already has budget %d for category %q", ErrConflict, other.ID, b.Category)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"subscription/internal/config"
+	"subscription/internal/model"
+	"subscription/internal/repository/memory"
+	"subscription/internal/service"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBudgetWebhookOncePerThreshold(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		thresholds []int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert struct {
+			Threshold int `json:"threshold"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("decode webhook: %v", err)
+		}
+		mu.Lock()
+		thresholds = append(thresholds, alert.Threshold)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{Budgets: config.Budgets{WebhookURL: server.URL, WebhookTimeout: time.Second}}
+	svc := service.NewSubscriptionService(memory.New(), logger, cfg)
+
+	// received дожидается отправок и возвращает пороги всех пришедших уведомлений.
+	received := func() []int {
+		t.Helper()
+		if err := svc.WaitWebhooks(ctx); err != nil {
+			t.Fatalf("wait webhooks: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), thresholds...)
+	}
+	setPrice := func(id, price int) {
+		t.Helper()
+		if _, err := svc.PatchSubscription(ctx, id, model.IfMatch{}, model.SubscriptionPatch{Price: &price}); err != nil {
+			t.Fatalf("patch price %d: %v", price, err)
+		}
+	}
+
+	if _, err := svc.CreateBudget(ctx, model.Budget{UserID: userA, Amount: 1000}); err != nil {
+		t.Fatalf("create budget: %v", err)
+	}
+	now := time.Now().UTC()
+	sub, err := svc.CreateSubscription(ctx, model.Subscription{ServiceName: "Netflix", Price: 900, UserID: userA,
+		StartDate: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	if got := received(); len(got) != 1 || got[0] != 80 {
+		t.Fatalf("after 90%%: webhooks %v, want [80]", got)
+	}
+
+	// Траты опускаются ниже 80% и снова его пересекают в том же месяце — повторного уведомления нет.
+	setPrice(sub.ID, 500)
+	setPrice(sub.ID, 950)
+	if got := received(); len(got) != 1 {
+		t.Fatalf("after crossing 80%% again: webhooks %v, want [80]", got)
+	}
+
+	setPrice(sub.ID, 1000)
+	setPrice(sub.ID, 1100)
+	if got := received(); len(got) != 2 || got[1] != 100 {
+		t.Fatalf("after 100%%: webhooks %v, want [80 100]", got)
+	}
+}
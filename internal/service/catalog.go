@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"subscription/internal/model"
+)
+
+// ListServices возвращает каталог сервисов.
+func (s *SubscriptionSvc) ListServices(ctx context.Context) (model.ServiceList, error) {
+	services, err := s.repo.ListServices(ctx)
+	if err != nil {
+		return model.ServiceList{}, err
+	}
+	if services == nil {
+		services = []model.Service{}
+	}
+	return model.ServiceList{Items: services}, nil
+}
+
+func (s *SubscriptionSvc) GetService(ctx context.Context, id int) (model.Service, error) {
+	return s.repo.GetService(ctx, id)
+}
+
+// CreateService добавляет сервис в каталог. Название и псевдонимы не должны совпадать
+// с названием или псевдонимом другого сервиса.
+func (s *SubscriptionSvc) CreateService(ctx context.Context, svc model.Service) (model.Service, error) {
+	const op = "internal.service.CreateService"
+	log := s.logger.With(slog.String("op", op))
+
+	svc = normalizeService(svc)
+	if err := validateService(svc); err != nil {
+		return model.Service{}, err
+	}
+
+	var created model.Service
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		if err := checkServiceKeys(ctx, repo, svc); err != nil {
+			return err
+		}
+		var err error
+		created, err = repo.CreateService(ctx, svc)
+		return err
+	})
+	if err != nil {
+		log.Error("Can`t create service", slog.String("error", err.Error()))
+		return model.Service{}, err
+	}
+	return created, nil
+}
+
+// UpdateService перезаписывает сервис. При смене названия старое остаётся псевдонимом, чтобы клиенты
+// со старым написанием попадали в тот же сервис, а действующие подписки в той же транзакции переводятся
+// на новое обычным путём изменения — с проверкой пересечений и событием update в журнале каждой.
+// Удалённые подписки получают новое название при восстановлении.
+func (s *SubscriptionSvc) UpdateService(ctx context.Context, svc model.Service) (model.Service, error) {
+	const op = "internal.service.UpdateService"
+	log := s.logger.With(slog.String("op", op))
+
+	svc = normalizeService(svc)
+	if err := validateService(svc); err != nil {
+		return model.Service{}, err
+	}
+
+	var updated model.Service
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		current, err := repo.GetService(ctx, svc.ID)
+		if err != nil {
+			return err
+		}
+		if current.Name != svc.Name {
+			svc = normalizeService(model.Service{
+				ID:        svc.ID,
+				Name:      svc.Name,
+				Aliases:   append(svc.Aliases, current.Name),
+				Category:  svc.Category,
+				VendorURL: svc.VendorURL,
+			})
+		}
+		if err := checkServiceKeys(ctx, repo, svc); err != nil {
+			return err
+		}
+		if updated, err = repo.UpdateService(ctx, svc); err != nil {
+			return err
+		}
+		if current.Name == svc.Name {
+			return nil
+		}
+		ids, err := repo.ListServiceSubscriptionIDs(ctx, current.Name)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			// Старое название уже псевдоним, resolveService в updateInTx переведёт подписку на новое.
+			_, err := updateInTx(ctx, repo, id, func(sub model.Subscription) (model.Subscription, error) {
+				return sub, nil
+			})
+			var overlap *OverlapError
+			if errors.As(err, &overlap) {
+				overlap.SubscriptionID = id
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("Can`t update service", slog.String("error", err.Error()))
+		return model.Service{}, err
+	}
+	return updated, nil
+}
+
+// DeleteService удаляет сервис из каталога; подписки сохраняют название.
+func (s *SubscriptionSvc) DeleteService(ctx context.Context, id int) error {
+	return s.repo.DeleteService(ctx, id)
+}
+
+// normalizeService обрезает пробелы, убирает псевдонимы, которые повторяют название или друг друга,
+// и упорядочивает остальные.
+func normalizeService(svc model.Service) model.Service {
+	svc.Name = strings.TrimSpace(svc.Name)
+	svc.Category = strings.TrimSpace(svc.Category)
+	svc.VendorURL = strings.TrimSpace(svc.VendorURL)
+
+	seen := map[string]bool{model.ServiceKey(svc.Name): true}
+	aliases := make([]string, 0, len(svc.Aliases))
+	for _, alias := range svc.Aliases {
+		alias = strings.TrimSpace(alias)
+		if key := model.ServiceKey(alias); key != "" && !seen[key] {
+			seen[key] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	slices.Sort(aliases)
+	svc.Aliases = aliases
+	return svc
+}
+
+func validateService(svc model.Service) error {
+	fields := make(map[string]string)
+	if svc.Name == "" {
+		fields["name"] = "is required"
+	}
+	if svc.VendorURL != "" {
+		u, err := url.Parse(svc.VendorURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fields["vendor_url"] = "must be an http(s) URL"
+		}
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// checkServiceKeys проверяет, что название и псевдонимы сервиса не заняты другим сервисом.
+func checkServiceKeys(ctx context.Context, repo SubscriptionRepository, svc model.Service) error {
+	for _, name := range append([]string{svc.Name}, svc.Aliases...) {
+		other, err := repo.FindServiceByKey(ctx, model.ServiceKey(name))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if other.ID != svc.ID {
+			return fmt.Errorf("%w: %q is already used by service %d", ErrConflict, name, other.ID)
+		}
+	}
+	return nil
+}
+
+// resolveService сводит название подписки к каноническому из каталога, а пустую категорию
+// заполняет категорией сервиса. Название, которого в каталоге нет, заносится в него при первом
+// использовании, без пробелов по краям: следующие подписки с другим написанием сведутся к нему.
+func resolveService(ctx context.Context, repo SubscriptionRepository, sub *model.Subscription) error {
+	svc, err := repo.FindServiceByKey(ctx, model.ServiceKey(sub.ServiceName))
+	if errors.Is(err, ErrNotFound) {
+		svc, err = repo.CreateService(ctx, model.Service{Name: strings.TrimSpace(sub.ServiceName), Aliases: []string{}})
+	}
+	if err != nil {
+		return err
+	}
+	sub.ServiceName = svc.Name
+	if sub.Category == "" {
+		sub.Category = strings.ToLower(svc.Category)
+	}
+	return nil
+}
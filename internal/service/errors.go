@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Виды ошибок, которые возвращают сервисы и хранилища. HTTP-слой различает их через
+// errors.Is и не знает о деталях конкретной БД.
+var (
+	// ErrNotFound — запрошенной записи нет в хранилище.
+	ErrNotFound = errors.New("not found")
+	// ErrValidation — входные данные не прошли проверку, подробности в *ValidationError.
+	ErrValidation = errors.New("validation failed")
+	// ErrConflict — операция противоречит текущему состоянию данных.
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed — запись изменилась с тех пор, как клиент её прочитал (не совпала версия).
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrIdempotencyKeyReused — Idempotency-Key уже использован с другим запросом.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used with a different request")
+	// ErrIdempotencyInProgress — первый запрос с этим Idempotency-Key ещё выполняется. Частный случай ErrConflict.
+	ErrIdempotencyInProgress = fmt.Errorf("%w: request with this idempotency key is still in progress", ErrConflict)
+	// ErrDuplicate — хранилище отклонило запись по уникальному ключу: такую же запись только что
+	// создал параллельный запрос. Повтор запроса увидит её. Частный случай ErrConflict.
+	ErrDuplicate = fmt.Errorf("%w: the record was created by a concurrent request, retry", ErrConflict)
+	// ErrUnavailable — хранилище временно недоступно, запрос можно повторить.
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+// ValidationError — ошибки проверки по полям: имя поля → причина.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+// NewValidationError — ошибка проверки одного поля.
+func NewValidationError(field, reason string) *ValidationError {
+	return &ValidationError{Fields: map[string]string{field: reason}}
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, field := range slices.Sorted(maps.Keys(e.Fields)) {
+		parts = append(parts, field+": "+e.Fields[field])
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// OverlapError — период подписки пересекается с другой подпиской того же пользователя
+// на тот же сервис. Частный случай ErrConflict.
+type OverlapError struct {
+	SubscriptionID int // id подписки, которую меняли; 0 — новая подписка или та, что в запросе
+	ConflictingID  int // id существующей подписки; 0, если определить не удалось
+}
+
+func (e *OverlapError) Error() string {
+	subject := "subscription"
+	if e.SubscriptionID != 0 {
+		subject = fmt.Sprintf("subscription %d", e.SubscriptionID)
+	}
+	if e.ConflictingID == 0 {
+		return subject + " overlaps with an existing one"
+	}
+	return fmt.Sprintf("%s overlaps with subscription %d", subject, e.ConflictingID)
+}
+
+func (e *OverlapError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Unavailable помечает ошибку хранилища как временную недоступность.
+func Unavailable(err error) error {
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"subscription/internal/model"
+	"time"
+)
+
+// fxDateLayout — формат даты начала действия курса (в API и CSV).
+const fxDateLayout = "2006-01-02"
+
+// ListFXRates возвращает все курсы валют по парам и датам.
+func (s *SubscriptionSvc) ListFXRates(ctx context.Context) (model.FXRateList, error) {
+	rates, err := s.repo.ListFXRates(ctx)
+	if err != nil {
+		return model.FXRateList{}, err
+	}
+	if rates == nil {
+		rates = []model.FXRate{}
+	}
+	return model.FXRateList{Items: rates}, nil
+}
+
+// SetFXRate записывает курс; курс той же пары с той же даты заменяется.
+func (s *SubscriptionSvc) SetFXRate(ctx context.Context, rate model.FXRate) (model.FXRate, error) {
+	const op = "internal.service.SetFXRate"
+	log := s.logger.With(slog.String("op", op))
+
+	rate = normalizeFXRate(rate)
+	if err := validateFXRate(rate); err != nil {
+		return model.FXRate{}, err
+	}
+	if err := s.repo.SetFXRates(ctx, []model.FXRate{rate}); err != nil {
+		log.Error("Can`t set fx rate", slog.String("error", err.Error()))
+		return model.FXRate{}, err
+	}
+	return rate, nil
+}
+
+func (s *SubscriptionSvc) DeleteFXRate(ctx context.Context, base, quote string, validFrom time.Time) error {
+	return s.repo.DeleteFXRate(ctx, strings.ToUpper(base), strings.ToUpper(quote), validFrom)
+}
+
+// ImportFXRates загружает курсы из CSV с заголовком base,quote,rate,valid_from (порядок колонок любой,
+// дата — YYYY-MM-DD). Файл применяется целиком или не применяется вовсе. Возвращает число курсов.
+func (s *SubscriptionSvc) ImportFXRates(ctx context.Context, r io.Reader) (int, error) {
+	const op = "internal.service.ImportFXRates"
+	log := s.logger.With(slog.String("op", op))
+
+	rates, err := parseFXRatesCSV(r)
+	if err != nil {
+		log.Error("Can`t parse fx rates", slog.String("error", err.Error()))
+		return 0, err
+	}
+	err = s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		return repo.SetFXRates(ctx, rates)
+	})
+	if err != nil {
+		log.Error("Can`t import fx rates", slog.String("error", err.Error()))
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+func parseFXRatesCSV(r io.Reader) ([]model.FXRate, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, NewValidationError("csv", "is empty")
+	}
+	if err != nil {
+		return nil, NewValidationError("csv", err.Error())
+	}
+	cols := make(map[string]int)
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"base", "quote", "rate", "valid_from"} {
+		if _, ok := cols[name]; !ok {
+			return nil, NewValidationError("csv", fmt.Sprintf("missing column %q", name))
+		}
+	}
+
+	var rates []model.FXRate
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, NewValidationError("csv", err.Error())
+		}
+		line, _ := cr.FieldPos(0)
+		prefix := fmt.Sprintf("line %d: ", line)
+
+		rate := model.FXRate{Base: rec[cols["base"]], Quote: rec[cols["quote"]]}
+		if rate.Rate, err = strconv.ParseFloat(strings.TrimSpace(rec[cols["rate"]]), 64); err != nil {
+			return nil, NewValidationError(prefix+"rate", "must be a number")
+		}
+		if rate.ValidFrom, err = time.Parse(fxDateLayout, strings.TrimSpace(rec[cols["valid_from"]])); err != nil {
+			return nil, NewValidationError(prefix+"valid_from", "must be YYYY-MM-DD")
+		}
+		rate = normalizeFXRate(rate)
+		if err := validateFXRate(rate); err != nil {
+			return nil, prefixFields(err, prefix)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// prefixFields дописывает префикс (номер строки CSV) к именам полей ошибки валидации.
+func prefixFields(err error, prefix string) error {
+	var v *ValidationError
+	if !errors.As(err, &v) {
+		return err
+	}
+	fields := make(map[string]string, len(v.Fields))
+	for name, reason := range v.Fields {
+		fields[prefix+name] = reason
+	}
+	return &ValidationError{Fields: fields}
+}
+
+func normalizeFXRate(rate model.FXRate) model.FXRate {
+	rate.Base = strings.ToUpper(strings.TrimSpace(rate.Base))
+	rate.Quote = strings.ToUpper(strings.TrimSpace(rate.Quote))
+	return rate
+}
+
+func validateFXRate(rate model.FXRate) error {
+	fields := make(map[string]string)
+	if !model.CurrencySupported(rate.Base) {
+		fields["base"] = "unsupported currency"
+	}
+	if !model.CurrencySupported(rate.Quote) {
+		fields["quote"] = "unsupported currency"
+	}
+	if rate.Base == rate.Quote {
+		fields["quote"] = "must differ from base"
+	}
+	if !(rate.Rate > 0) || math.IsInf(rate.Rate, 0) {
+		fields["rate"] = "must be positive"
+	}
+	if rate.ValidFrom.IsZero() {
+		fields["valid_from"] = "is required"
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// fxConverter пересчитывает суммы между валютами по курсам, действовавшим на дату.
+type fxConverter struct {
+	rates      map[[2]string][]model.FXRate // пара base/quote → курсы по возрастанию ValidFrom
+	currencies []string
+}
+
+func newFXConverter(rates []model.FXRate) *fxConverter {
+	c := &fxConverter{rates: make(map[[2]string][]model.FXRate)}
+	for _, r := range rates {
+		pair := [2]string{r.Base, r.Quote}
+		c.rates[pair] = append(c.rates[pair], r)
+		for _, code := range pair {
+			if !slices.Contains(c.currencies, code) {
+				c.currencies = append(c.currencies, code)
+			}
+		}
+	}
+	for _, list := range c.rates {
+		slices.SortFunc(list, func(a, b model.FXRate) int { return a.ValidFrom.Compare(b.ValidFrom) })
+	}
+	slices.Sort(c.currencies)
+	return c
+}
+
+// convert переводит сумму в минимальных единицах from в минимальные единицы to по курсу на дату at.
+func (c *fxConverter) convert(amount float64, from, to string, at time.Time) (float64, error) {
+	rate, ok := c.rate(from, to, at)
+	if !ok {
+		return 0, NewValidationError("currency",
+			fmt.Sprintf("no %s/%s exchange rate on %s", from, to, at.Format(fxDateLayout)))
+	}
+	return amount / model.MinorUnits(from) * rate * model.MinorUnits(to), nil
+}
+
+// rate — курс from→to на дату at: прямой, обратный или кросс-курс через третью валюту.
+func (c *fxConverter) rate(from, to string, at time.Time) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	if r, ok := c.pairRate(from, to, at); ok {
+		return r, true
+	}
+	for _, via := range c.currencies {
+		if via == from || via == to {
+			continue
+		}
+		r1, ok1 := c.pairRate(from, via, at)
+		r2, ok2 := c.pairRate(via, to, at)
+		if ok1 && ok2 {
+			return r1 * r2, true
+		}
+	}
+	return 0, false
+}
+
+// pairRate ищет прямой курс пары или обратный к нему.
+func (c *fxConverter) pairRate(from, to string, at time.Time) (float64, bool) {
+	if r, ok := rateAt(c.rates[[2]string{from, to}], at); ok {
+		return r, true
+	}
+	if r, ok := rateAt(c.rates[[2]string{to, from}], at); ok {
+		return 1 / r, true
+	}
+	return 0, false
+}
+
+// rateAt — последний курс, вступивший в силу не позже at.
+func rateAt(rates []model.FXRate, at time.Time) (float64, bool) {
+	var rate float64
+	found := false
+	for _, r := range rates {
+		if r.ValidFrom.After(at) {
+			break
+		}
+		rate, found = r.Rate, true
+	}
+	return rate, found
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"subscription/internal/config"
+	"subscription/internal/model"
+	"time"
+)
+
+// IdempotencyRepository — контракт хранилища ключей идемпотентности.
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey сохраняет rec без ответа и возвращает (rec, true). Если ключ уже есть
+	// и не истёк к моменту rec.CreatedAt, ничего не меняет и возвращает сохранённую запись и false.
+	// Истёкшая запись заменяется новой.
+	ReserveIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) (model.IdempotencyRecord, bool, error)
+
+	// SaveIdempotentResponse дописывает ответ к зарезервированному ключу.
+	SaveIdempotentResponse(ctx context.Context, key string, resp model.IdempotentResponse) error
+
+	// DeleteIdempotencyKey снимает резерв, чтобы запрос можно было повторить.
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+
+	// DeleteExpiredIdempotencyKeys удаляет ключи, истёкшие к моменту now, и возвращает их число.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int, error)
+}
+
+// IdempotencySvc дедуплицирует повторы запросов с одинаковым Idempotency-Key:
+// первый запрос выполняется, его ответ хранится TTL и отдаётся повторам.
+type IdempotencySvc struct {
+	repo   IdempotencyRepository
+	logger *slog.Logger
+	ttl    time.Duration
+}
+
+func NewIdempotencyService(repo IdempotencyRepository, logger *slog.Logger, config *config.Config) *IdempotencySvc {
+	return &IdempotencySvc{
+		repo:   repo,
+		logger: logger,
+		ttl:    config.Idempotency.TTL,
+	}
+}
+
+// Begin резервирует ключ. Возвращает nil, если запрос выполняется впервые, и сохранённый
+// ответ для повтора. ErrIdempotencyKeyReused — ключ пришёл с другим запросом,
+// ErrIdempotencyInProgress — первый запрос с этим ключом ещё не завершён.
+func (s *IdempotencySvc) Begin(ctx context.Context, key, requestHash string) (*model.IdempotentResponse, error) {
+	now := time.Now().UTC()
+	rec, reserved, err := s.repo.ReserveIdempotencyKey(ctx, model.IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case reserved:
+		return nil, nil
+	case rec.RequestHash != requestHash:
+		return nil, ErrIdempotencyKeyReused
+	case rec.Response == nil:
+		return nil, ErrIdempotencyInProgress
+	}
+	return rec.Response, nil
+}
+
+// Complete сохраняет ответ первого запроса.
+func (s *IdempotencySvc) Complete(ctx context.Context, key string, resp model.IdempotentResponse) error {
+	return s.repo.SaveIdempotentResponse(ctx, key, resp)
+}
+
+// Release снимает резерв: запрос не выполнен, и клиент может повторить его с тем же ключом.
+func (s *IdempotencySvc) Release(ctx context.Context, key string) error {
+	return s.repo.DeleteIdempotencyKey(ctx, key)
+}
+
+// RunSweeper раз в interval удаляет истёкшие ключи, пока не отменён ctx.
+// interval <= 0 отключает очистку.
+func (s *IdempotencySvc) RunSweeper(ctx context.Context, interval time.Duration) {
+	const op = "internal.service.IdempotencySweeper"
+	log := s.logger.With(slog.String("op", op))
+
+	if interval <= 0 {
+		log.Warn("idempotency sweeper disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.repo.DeleteExpiredIdempotencyKeys(ctx, time.Now().UTC())
+			if err != nil {
+				log.Error("Can`t delete expired idempotency keys", slog.String("error", err.Error()))
+				continue
+			}
+			if n > 0 {
+				log.Debug("expired idempotency keys deleted", slog.Int("count", n))
+			}
+		}
+	}
+}
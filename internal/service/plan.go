@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"subscription/internal/model"
+)
+
+// ListPlans возвращает тарифы сервиса; ErrNotFound, если сервиса нет.
+func (s *SubscriptionSvc) ListPlans(ctx context.Context, serviceID int) (model.PlanList, error) {
+	if _, err := s.repo.GetService(ctx, serviceID); err != nil {
+		return model.PlanList{}, err
+	}
+	plans, err := s.repo.ListPlans(ctx, serviceID)
+	if err != nil {
+		return model.PlanList{}, err
+	}
+	if plans == nil {
+		plans = []model.Plan{}
+	}
+	return model.PlanList{Items: plans}, nil
+}
+
+// GetPlan возвращает тариф сервиса; тариф другого сервиса — ErrNotFound.
+func (s *SubscriptionSvc) GetPlan(ctx context.Context, serviceID, planID int) (model.Plan, error) {
+	return servicePlan(ctx, s.repo, serviceID, planID)
+}
+
+// CreatePlan добавляет тариф сервису; название тарифа уникально в пределах сервиса.
+func (s *SubscriptionSvc) CreatePlan(ctx context.Context, plan model.Plan) (model.Plan, error) {
+	const op = "internal.service.CreatePlan"
+	log := s.logger.With(slog.String("op", op))
+
+	plan = normalizePlan(plan)
+	if err := validatePlan(plan); err != nil {
+		return model.Plan{}, err
+	}
+
+	var created model.Plan
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		if _, err := repo.GetService(ctx, plan.ServiceID); err != nil {
+			return err
+		}
+		if err := checkPlanName(ctx, repo, plan); err != nil {
+			return err
+		}
+		var err error
+		created, err = repo.CreatePlan(ctx, plan)
+		return err
+	})
+	if err != nil {
+		log.Error("Can`t create plan", slog.String("error", err.Error()))
+		return model.Plan{}, err
+	}
+	return created, nil
+}
+
+// UpdatePlan меняет название и цену тарифа. Подписки на тариф сохраняют свои цены.
+func (s *SubscriptionSvc) UpdatePlan(ctx context.Context, plan model.Plan) (model.Plan, error) {
+	const op = "internal.service.UpdatePlan"
+	log := s.logger.With(slog.String("op", op))
+
+	plan = normalizePlan(plan)
+	if err := validatePlan(plan); err != nil {
+		return model.Plan{}, err
+	}
+
+	var updated model.Plan
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		if _, err := servicePlan(ctx, repo, plan.ServiceID, plan.ID); err != nil {
+			return err
+		}
+		if err := checkPlanName(ctx, repo, plan); err != nil {
+			return err
+		}
+		var err error
+		updated, err = repo.UpdatePlan(ctx, plan)
+		return err
+	})
+	if err != nil {
+		log.Error("Can`t update plan", slog.String("error", err.Error()))
+		return model.Plan{}, err
+	}
+	return updated, nil
+}
+
+// DeletePlan удаляет тариф сервиса; подписки на него остаются без тарифа с прежней ценой.
+func (s *SubscriptionSvc) DeletePlan(ctx context.Context, serviceID, planID int) error {
+	return s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		if _, err := servicePlan(ctx, repo, serviceID, planID); err != nil {
+			return err
+		}
+		return repo.DeletePlan(ctx, planID)
+	})
+}
+
+// servicePlan читает тариф и проверяет, что он относится к сервису serviceID.
+func servicePlan(ctx context.Context, repo SubscriptionRepository, serviceID, planID int) (model.Plan, error) {
+	plan, err := repo.GetPlan(ctx, planID)
+	if err != nil {
+		return model.Plan{}, err
+	}
+	if plan.ServiceID != serviceID {
+		return model.Plan{}, ErrNotFound
+	}
+	return plan, nil
+}
+
+func normalizePlan(plan model.Plan) model.Plan {
+	plan.Name = strings.TrimSpace(plan.Name)
+	plan.Currency = strings.ToUpper(strings.TrimSpace(plan.Currency))
+	if plan.Currency == "" {
+		plan.Currency = model.DefaultCurrency
+	}
+	return plan
+}
+
+func validatePlan(plan model.Plan) error {
+	fields := make(map[string]string)
+	if plan.Name == "" {
+		fields["name"] = "is required"
+	}
+	validateAmount(fields, "price_minor", plan.Price)
+	if !model.CurrencySupported(plan.Currency) {
+		fields["currency"] = "unsupported currency"
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// checkPlanName не даёт завести у сервиса два тарифа, различающихся только регистром.
+func checkPlanName(ctx context.Context, repo SubscriptionRepository, plan model.Plan) error {
+	plans, err := repo.ListPlans(ctx, plan.ServiceID)
+	if err != nil {
+		return err
+	}
+	for _, other := range plans {
+		if other.ID != plan.ID && strings.EqualFold(other.Name, plan.Name) {
+			return fmt.Errorf("%w: plan %q already exists as %d", ErrConflict, plan.Name, other.ID)
+		}
+	}
+	return nil
+}
+
+// applyPlan проверяет, что тариф подписки относится к её сервису, и подставляет цену
+// и валюту тарифа, если цена не указана (sub.PriceFromPlan). Явно указанная валюта должна
+// совпасть с валютой тарифа. Название сервиса к этому моменту уже сведено к каталогу.
+func applyPlan(ctx context.Context, repo SubscriptionRepository, sub *model.Subscription) error {
+	if sub.PlanID == nil {
+		return nil
+	}
+	plan, err := repo.GetPlan(ctx, *sub.PlanID)
+	if errors.Is(err, ErrNotFound) {
+		return NewValidationError("plan_id", "plan not found")
+	}
+	if err != nil {
+		return err
+	}
+	svc, err := repo.GetService(ctx, plan.ServiceID)
+	if err != nil {
+		return err
+	}
+	if svc.Name != sub.ServiceName {
+		return NewValidationError("plan_id", fmt.Sprintf("plan belongs to service %q", svc.Name))
+	}
+	if sub.PriceFromPlan {
+		if sub.Currency != "" && sub.Currency != plan.Currency {
+			return NewValidationError("currency", fmt.Sprintf("must match plan currency %s", plan.Currency))
+		}
+		sub.Price, sub.Currency, sub.PriceFromPlan = plan.Price, plan.Currency, false
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"subscription/internal/model"
+)
+
+// SchedulePriceChange планирует новую цену подписки с месяца ch.EffectiveFrom. Изменение
+// с того же месяца заменяется. Месяц должен попадать в период подписки.
+func (s *SubscriptionSvc) SchedulePriceChange(ctx context.Context, ch model.PriceChange) (model.PriceChange, error) {
+	const op = "internal.service.SchedulePriceChange"
+	log := s.logger.With(slog.String("op", op))
+
+	fields := make(map[string]string)
+	if ch.EffectiveFrom.IsZero() {
+		fields["effective_from"] = "is required"
+	}
+	validateAmount(fields, "price_minor", ch.Price)
+	if len(fields) > 0 {
+		return model.PriceChange{}, &ValidationError{Fields: fields}
+	}
+	ch.EffectiveFrom = model.MonthFromIndex(model.MonthIndex(ch.EffectiveFrom))
+
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		sub, err := repo.LockSubscription(ctx, ch.SubscriptionID, false)
+		if err != nil {
+			return err
+		}
+		if sub.ActiveMonths(ch.EffectiveFrom, ch.EffectiveFrom) == 0 {
+			return NewValidationError("effective_from", "must be within the subscription period")
+		}
+
+		changes, err := repo.ListPriceChanges(ctx, []int{ch.SubscriptionID})
+		if err != nil {
+			return err
+		}
+		var prev any
+		for _, c := range changes {
+			if model.MonthIndex(c.EffectiveFrom) == model.MonthIndex(ch.EffectiveFrom) {
+				prev = c
+			}
+		}
+
+		if err := repo.SetPriceChange(ctx, ch); err != nil {
+			return err
+		}
+		return recordEvent(ctx, repo, model.EventPriceChange, ch.SubscriptionID, prev, ch)
+	})
+	if err != nil {
+		log.Error("Can`t schedule price change", slog.String("error", err.Error()))
+		return model.PriceChange{}, err
+	}
+	return ch, nil
+}
+
+// PriceSchedule возвращает график цен подписки. Без изменений — пустой список:
+// весь период действует цена из самой подписки.
+func (s *SubscriptionSvc) PriceSchedule(ctx context.Context, id int) (model.PriceChangeList, error) {
+	if _, err := s.repo.GetSubscription(ctx, id, false); err != nil {
+		return model.PriceChangeList{}, err
+	}
+	changes, err := s.repo.ListPriceChanges(ctx, []int{id})
+	if err != nil {
+		return model.PriceChangeList{}, err
+	}
+	if changes == nil {
+		changes = []model.PriceChange{}
+	}
+	return model.PriceChangeList{Items: changes}, nil
+}
+
+// priceSchedules загружает графики цен подписок, попавших в расчёт.
+func (s *SubscriptionSvc) priceSchedules(ctx context.Context, subs []*model.Subscription) (map[int]model.PriceSchedule, error) {
+	ids := make([]int, len(subs))
+	for i, sub := range subs {
+		ids[i] = sub.ID
+	}
+	changes, err := s.repo.ListPriceChanges(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return model.GroupPriceChanges(changes), nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"math"
+	"slices"
+	"subscription/internal/model"
+	"time"
+)
+
+// Renewals перечисляет списания подписок пользователя, даты которых попали в месяцы [from, to]
+// (включительно). Даты и цены считаются так же, как Sum в режиме cash, поэтому Total совпадает
+// с её итогом за тот же период. Валюта итога выбирается по тем же правилам, что и у Sum.
+// Период — не больше model.MaxRenewalMonths месяцев.
+func (s *SubscriptionSvc) Renewals(ctx context.Context, userID string, from, to time.Time, currency string) (model.RenewalCalendar, error) {
+	const op = "internal.service.Renewals"
+	log := s.logger.With(slog.String("op", op))
+
+	if err := uuid.Validate(userID); err != nil {
+		return model.RenewalCalendar{}, NewValidationError("user_id", "must be a UUID")
+	}
+	if to.Before(from) {
+		return model.RenewalCalendar{}, NewValidationError("to", "cannot be before from")
+	}
+	if model.MonthIndex(to)-model.MonthIndex(from) >= model.MaxRenewalMonths {
+		return model.RenewalCalendar{}, NewValidationError("to", fmt.Sprintf("must be within %d months of from", model.MaxRenewalMonths))
+	}
+
+	subs, err := s.repo.ListActiveSubscriptions(ctx, model.SubscriptionFilter{UserIDs: []string{userID}}, from, to)
+	if err != nil {
+		log.Error("Can`t list active subscriptions", slog.String("error", err.Error()))
+		return model.RenewalCalendar{}, err
+	}
+	schedules, err := s.priceSchedules(ctx, subs)
+	if err != nil {
+		log.Error("Can`t list price changes", slog.String("error", err.Error()))
+		return model.RenewalCalendar{}, err
+	}
+	currency, err = summaryCurrency(subs, currency)
+	if err != nil {
+		return model.RenewalCalendar{}, err
+	}
+	conv, err := s.fxConverter(ctx, subs, currency)
+	if err != nil {
+		log.Error("Can`t list fx rates", slog.String("error", err.Error()))
+		return model.RenewalCalendar{}, err
+	}
+
+	calendar := model.RenewalCalendar{UserID: userID, Currency: currency, Items: []model.Renewal{}}
+	// Итог округляется один раз, как в Sum.
+	var total float64
+	for _, sub := range subs {
+		for _, c := range subscriptionCharges(*sub, schedules[sub.ID], from, to) {
+			calendar.Items = append(calendar.Items, model.Renewal{
+				SubscriptionID: sub.ID,
+				ServiceName:    sub.ServiceName,
+				Date:           c.at,
+				Price:          c.price,
+				Currency:       sub.Currency,
+				Intro:          c.intro,
+			})
+			converted, err := conv.convert(float64(c.price), sub.Currency, currency, model.MonthFromIndex(model.MonthIndex(c.at)))
+			if err != nil {
+				return model.RenewalCalendar{}, err
+			}
+			total += converted
+		}
+	}
+	calendar.Total = int(math.Round(total))
+	slices.SortStableFunc(calendar.Items, func(a, b model.Renewal) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.SubscriptionID, b.SubscriptionID))
+	})
+
+	return calendar, nil
+}
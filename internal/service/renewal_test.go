@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"subscription/internal/model"
+	"subscription/internal/service"
+	"testing"
+	"time"
+)
+
+func TestRenewals(t *testing.T) {
+	ctx := context.Background()
+	svc := newMemoryService(t)
+
+	// 30 дней пробного периода с 1 января: списания с 31 января, дальше в последний день
+	// коротких месяцев, а не 3 марта и 1 мая.
+	monthly, err := svc.CreateSubscription(ctx, model.Subscription{ServiceName: "monthly", Price: 1000, UserID: userA,
+		StartDate: month(t, "01-2025"), Trial: &model.Trial{Unit: model.TrialDay, Count: 30}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	quarterly, err := svc.CreateSubscription(ctx, model.Subscription{ServiceName: "quarterly", Price: 3000, UserID: userA,
+		StartDate: month(t, "02-2025"), BillingPeriod: model.BillingPeriod{Unit: model.BillingQuarter, Count: 1},
+		Intro: &model.IntroPrice{Price: 300, Periods: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Другой пользователь в календарь не попадает.
+	if _, err := svc.CreateSubscription(ctx, model.Subscription{ServiceName: "monthly", Price: 500, UserID: userB,
+		StartDate: month(t, "01-2025")}); err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := month(t, "01-2025"), month(t, "06-2025")
+	calendar, err := svc.Renewals(ctx, userA, from, to, "")
+	if err != nil {
+		t.Fatalf("renewals: %v", err)
+	}
+
+	want := []model.Renewal{
+		{SubscriptionID: monthly.ID, Date: date(t, "2025-01-31"), Price: 1000},
+		{SubscriptionID: quarterly.ID, Date: date(t, "2025-02-01"), Price: 300, Intro: true},
+		{SubscriptionID: monthly.ID, Date: date(t, "2025-02-28"), Price: 1000},
+		{SubscriptionID: monthly.ID, Date: date(t, "2025-03-31"), Price: 1000},
+		{SubscriptionID: monthly.ID, Date: date(t, "2025-04-30"), Price: 1000},
+		{SubscriptionID: quarterly.ID, Date: date(t, "2025-05-01"), Price: 3000},
+		{SubscriptionID: monthly.ID, Date: date(t, "2025-05-31"), Price: 1000},
+		{SubscriptionID: monthly.ID, Date: date(t, "2025-06-30"), Price: 1000},
+	}
+	if len(calendar.Items) != len(want) {
+		t.Fatalf("got %d renewals, want %d: %+v", len(calendar.Items), len(want), calendar.Items)
+	}
+	for i, w := range want {
+		got := calendar.Items[i]
+		if got.SubscriptionID != w.SubscriptionID || !got.Date.Equal(w.Date) || got.Price != w.Price || got.Intro != w.Intro {
+			t.Errorf("renewal %d = {sub %d, %s, %d, intro %t}, want {sub %d, %s, %d, intro %t}", i,
+				got.SubscriptionID, got.Date.Format(time.DateOnly), got.Price, got.Intro,
+				w.SubscriptionID, w.Date.Format(time.DateOnly), w.Price, w.Intro)
+		}
+	}
+	if calendar.Total != 6*1000+300+3000 {
+		t.Errorf("total = %d, want %d", calendar.Total, 6*1000+300+3000)
+	}
+
+	summary, err := svc.Sum(ctx, model.SubscriptionFilter{UserIDs: []string{userA}}, from, to, model.SummaryOptions{Mode: model.SumCash})
+	if err != nil {
+		t.Fatalf("sum: %v", err)
+	}
+	if summary.Total != calendar.Total {
+		t.Errorf("cash summary total = %d, calendar total %d", summary.Total, calendar.Total)
+	}
+}
+
+func TestRenewalsWindow(t *testing.T) {
+	svc := newMemoryService(t)
+	from := month(t, "01-2025")
+	if _, err := svc.Renewals(context.Background(), userA, from, from.AddDate(0, model.MaxRenewalMonths-1, 0), ""); err != nil {
+		t.Errorf("%d months: %v", model.MaxRenewalMonths, err)
+	}
+	_, err := svc.Renewals(context.Background(), userA, from, from.AddDate(0, model.MaxRenewalMonths, 0), "")
+	if !errors.Is(err, service.ErrValidation) {
+		t.Errorf("%d months: err = %v, want validation error", model.MaxRenewalMonths+1, err)
+	}
+}
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		t.Fatalf("parse date %q: %v", s, err)
+	}
+	return d
+}
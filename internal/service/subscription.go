@@ -2,27 +2,138 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
 	"subscription/internal/config"
 	"subscription/internal/model"
-	"subscription/internal/repository/postgres"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // SubscriptionRepository — контракт ХРАНИЛИЩА данных
 type SubscriptionRepository interface {
 	CreateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error)
 
-	GetSubscription(ctx context.Context, id int) (model.Subscription, error)
+	// GetSubscription возвращает ErrNotFound и для мягко удалённой подписки, если не задан includeDeleted.
+	GetSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error)
 
-	ListSubscriptions(ctx context.Context, userID, serviceName string) ([]*model.Subscription, error)
+	// LockSubscription — GetSubscription с блокировкой строки до конца транзакции.
+	LockSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error)
 
-	UpdateSubscription(ctx context.Context, sub model.Subscription) error
+	ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Page) ([]*model.Subscription, error)
 
-	DeleteSubscription(ctx context.Context, id int) error
+	// UpdateSubscription и DeleteSubscription возвращают ErrNotFound, если подписки нет или она удалена,
+	// и ErrPreconditionFailed, если ожидаемая версия (sub.Version, version) не 0 и не совпала.
+	// Удаление мягкое: подписка получает deleted_at и пропадает из выборок.
+	UpdateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error)
 
-	Sum(ctx context.Context, userID, serviceName string, startPeriod, endPeriod time.Time) (int, error)
+	DeleteSubscription(ctx context.Context, id, version int) error
+
+	// RestoreSubscription снимает отметку об удалении; ErrNotFound, если удалённой подписки с таким id нет.
+	RestoreSubscription(ctx context.Context, id int) (model.Subscription, error)
+
+	// PurgeDeletedSubscriptions окончательно удаляет подписки, удалённые не позже before, и возвращает их число.
+	PurgeDeletedSubscriptions(ctx context.Context, before time.Time) (int, error)
+
+	ListActiveSubscriptions(ctx context.Context, filter model.SubscriptionFilter, startPeriod, endPeriod time.Time) ([]*model.Subscription, error)
+
+	// SumSubscriptions считает агрегат стоимости подписок без поправок (см. model.SumQuery) на стороне
+	// хранилища, не загружая сами подписки; q.Filter.Adjusted не учитывается.
+	SumSubscriptions(ctx context.Context, q model.SumQuery) ([]model.SumRow, error)
+
+	// FindOverlappingSubscription возвращает id подписки того же пользователя на тот же сервис,
+	// период которой пересекается с sub (сама sub.ID не учитывается), или 0, если таких нет.
+	FindOverlappingSubscription(ctx context.Context, sub model.Subscription) (int, error)
+
+	// AddSubscriptionEvent дописывает запись в журнал изменений.
+	AddSubscriptionEvent(ctx context.Context, ev model.SubscriptionEvent) error
+
+	// ListSubscriptionEvents возвращает журнал подписки в порядке записи.
+	ListSubscriptionEvents(ctx context.Context, subscriptionID int) ([]model.SubscriptionEvent, error)
+
+	// SetPriceChange записывает изменение цены подписки; изменение с того же месяца заменяется.
+	SetPriceChange(ctx context.Context, ch model.PriceChange) error
+
+	// ListPriceChanges возвращает изменения цен перечисленных подписок,
+	// упорядоченные по subscription_id и effective_from.
+	ListPriceChanges(ctx context.Context, subscriptionIDs []int) ([]model.PriceChange, error)
+
+	// SetFXRates записывает курсы валют; курс той же пары с той же даты заменяется.
+	SetFXRates(ctx context.Context, rates []model.FXRate) error
+
+	// ListFXRates возвращает все курсы, упорядоченные по base, quote и valid_from.
+	ListFXRates(ctx context.Context) ([]model.FXRate, error)
+
+	// DeleteFXRate удаляет курс; ErrNotFound, если его нет.
+	DeleteFXRate(ctx context.Context, base, quote string, validFrom time.Time) error
+
+	// CreateService добавляет сервис в каталог вместе с псевдонимами.
+	CreateService(ctx context.Context, svc model.Service) (model.Service, error)
+
+	// GetService возвращает ErrNotFound, если сервиса нет.
+	GetService(ctx context.Context, id int) (model.Service, error)
+
+	// FindServiceByKey ищет сервис по ключу (model.ServiceKey) названия или псевдонима; ErrNotFound, если нет.
+	FindServiceByKey(ctx context.Context, key string) (model.Service, error)
+
+	// ListServices возвращает каталог, упорядоченный по названию.
+	ListServices(ctx context.Context) ([]model.Service, error)
+
+	// UpdateService перезаписывает сервис и заменяет его псевдонимы; ErrNotFound, если сервиса нет.
+	UpdateService(ctx context.Context, svc model.Service) (model.Service, error)
+
+	// DeleteService удаляет сервис с псевдонимами и тарифами; подписки остаются без тарифа. ErrNotFound, если сервиса нет.
+	DeleteService(ctx context.Context, id int) error
+
+	// ListServiceSubscriptionIDs возвращает id неудалённых подписок с названием сервиса name, по возрастанию.
+	ListServiceSubscriptionIDs(ctx context.Context, name string) ([]int, error)
+
+	// CreatePlan добавляет тариф сервиса.
+	CreatePlan(ctx context.Context, plan model.Plan) (model.Plan, error)
+
+	// GetPlan возвращает ErrNotFound, если тарифа нет.
+	GetPlan(ctx context.Context, id int) (model.Plan, error)
+
+	// ListPlans возвращает тарифы сервиса, упорядоченные по названию.
+	ListPlans(ctx context.Context, serviceID int) ([]model.Plan, error)
+
+	// UpdatePlan перезаписывает название и цену тарифа; ErrNotFound, если тарифа нет.
+	UpdatePlan(ctx context.Context, plan model.Plan) (model.Plan, error)
+
+	// DeletePlan удаляет тариф; подписки на него остаются без тарифа. ErrNotFound, если тарифа нет.
+	DeletePlan(ctx context.Context, id int) error
+
+	// CreateBudget добавляет бюджет; бюджет на того же пользователя и категорию — ErrConflict.
+	CreateBudget(ctx context.Context, b model.Budget) (model.Budget, error)
+
+	// GetBudget возвращает ErrNotFound, если бюджета нет.
+	GetBudget(ctx context.Context, id int) (model.Budget, error)
+
+	// ListBudgets возвращает бюджеты пользователя, а с пустым userID — все, упорядоченные по user_id и категории.
+	ListBudgets(ctx context.Context, userID string) ([]model.Budget, error)
+
+	// UpdateBudget перезаписывает категорию, сумму и валюту бюджета; ErrNotFound, если бюджета нет.
+	UpdateBudget(ctx context.Context, b model.Budget) (model.Budget, error)
+
+	// DeleteBudget удаляет бюджет вместе с уведомлениями; ErrNotFound, если бюджета нет.
+	DeleteBudget(ctx context.Context, id int) error
+
+	// AddBudgetAlert записывает уведомление, если для его бюджета, месяца и порога его ещё нет,
+	// и сообщает, записано ли оно.
+	AddBudgetAlert(ctx context.Context, alert model.BudgetAlert) (model.BudgetAlert, bool, error)
+
+	// ListBudgetAlerts возвращает уведомления бюджета в порядке записи.
+	ListBudgetAlerts(ctx context.Context, budgetID int) ([]model.BudgetAlert, error)
+
+	// InTx выполняет fn в одной транзакции: все вызовы repo внутри fn идут в неё,
+	// ошибка fn откатывает транзакцию.
+	InTx(ctx context.Context, fn func(repo SubscriptionRepository) error) error
 
 	Ping(ctx context.Context) error
 }
@@ -31,63 +142,429 @@ type SubscriptionSvc struct {
 	repo   SubscriptionRepository
 	logger *slog.Logger
 	config *config.Config
+	// webhook отправляет уведомления бюджетов; webhooks — отправки, которые ещё идут (см. WaitWebhooks).
+	webhook  *http.Client
+	webhooks sync.WaitGroup
 }
 
 func NewSubscriptionService(repo SubscriptionRepository, logger *slog.Logger, config *config.Config) *SubscriptionSvc {
 	return &SubscriptionSvc{
-		repo:   repo,
-		logger: logger,
-		config: config,
+		repo:    repo,
+		logger:  logger,
+		config:  config,
+		webhook: &http.Client{Timeout: config.Budgets.WebhookTimeout},
 	}
 }
 
+// CreateSubscription создаёт подписку и пишет событие create в журнал в той же транзакции.
+// Название сервиса сводится к каноническому из каталога, тариф проверяется по сервису,
+// без своей категории подписка получает категорию сервиса. После записи проверяются бюджеты пользователя.
 func (s *SubscriptionSvc) CreateSubscription(ctx context.Context, sub model.Subscription) (model.Subscription, error) {
-	// по хорошему на этом этапе нужно проверять, а не дубль ли это? Не пересекается ли подпсика по времени с новой?
-
 	const op = "internal.service.CreateSubscription"
 	log := s.logger.With(slog.String("op", op))
 
-	if sub.Price < 0 {
-		err := fmt.Errorf("price cannot be negative")
+	sub = normalizeSubscription(sub)
+	if err := validateSubscription(sub); err != nil {
 		log.Error("Can`t create new subscription", slog.String("error", err.Error()))
 		return model.Subscription{}, err
 	}
 
-	sub, err := s.repo.CreateSubscription(ctx, sub)
+	var created model.Subscription
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		if err := resolveService(ctx, repo, &sub); err != nil {
+			return err
+		}
+		if err := applyPlan(ctx, repo, &sub); err != nil {
+			return err
+		}
+		if err := checkOverlap(ctx, repo, sub); err != nil {
+			return err
+		}
+		var err error
+		if created, err = repo.CreateSubscription(ctx, sub); err != nil {
+			return err
+		}
+		return recordEvent(ctx, repo, model.EventCreate, created.ID, nil, &created)
+	})
 	if err != nil {
+		err = s.resolveOverlap(ctx, sub, err)
 		log.Error("Can`t create new subscription", slog.String("error", err.Error()))
+		return model.Subscription{}, err
+	}
+
+	s.checkBudgets(ctx, created)
+	return created, nil
+}
+
+func (s *SubscriptionSvc) GetSubscription(ctx context.Context, id int, includeDeleted bool) (model.Subscription, error) {
+	return s.repo.GetSubscription(ctx, id, includeDeleted)
+}
+
+// UpdateSubscription перезаписывает подписку целиком; текущая версия должна подходить к ifMatch.
+func (s *SubscriptionSvc) UpdateSubscription(ctx context.Context, sub model.Subscription, ifMatch model.IfMatch) (model.Subscription, error) {
+	const op = "internal.service.UpdateSubscription"
+	log := s.logger.With(slog.String("op", op))
+
+	sub = normalizeSubscription(sub)
+	if err := validateSubscription(sub); err != nil {
+		log.Error("Can`t update subscription", slog.String("error", err.Error()))
+		return model.Subscription{}, err
+	}
+
+	updated, err := s.update(ctx, sub.ID, func(current model.Subscription) (model.Subscription, error) {
+		if !ifMatch.Matches(current.Version) {
+			return sub, ErrPreconditionFailed
+		}
+		return sub, nil
+	})
+	if err != nil {
+		log.Error("Can`t update subscription", slog.String("error", err.Error()))
+		return model.Subscription{}, err
+	}
+	return updated, nil
+}
+
+// PatchSubscription применяет частичное обновление. Чтение, слияние, проверка и запись
+// идут в одной транзакции, строка заблокирована: параллельная правка другого поля
+// не затрёт результат. Текущая версия должна подходить к ifMatch.
+func (s *SubscriptionSvc) PatchSubscription(ctx context.Context, id int, ifMatch model.IfMatch, patch model.SubscriptionPatch) (model.Subscription, error) {
+	const op = "internal.service.PatchSubscription"
+	log := s.logger.With(slog.String("op", op))
+
+	updated, err := s.update(ctx, id, func(current model.Subscription) (model.Subscription, error) {
+		if !ifMatch.Matches(current.Version) {
+			return current, ErrPreconditionFailed
+		}
+		sub := normalizeSubscription(patch.Apply(current))
+		return sub, validateSubscription(sub)
+	})
+	if err != nil {
+		log.Error("Can`t patch subscription", slog.String("error", err.Error()))
+		return model.Subscription{}, err
+	}
+	return updated, nil
+}
+
+// update — общий путь PUT и PATCH: updateInTx в своей транзакции, а после фиксации — проверка бюджетов.
+func (s *SubscriptionSvc) update(ctx context.Context, id int, change func(current model.Subscription) (model.Subscription, error)) (model.Subscription, error) {
+	var sub, updated model.Subscription
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		var err error
+		sub, err = updateInTx(ctx, repo, id, change)
+		updated = sub
+		return err
+	})
+	if err != nil {
+		return model.Subscription{}, s.resolveOverlap(ctx, sub, err)
+	}
+	s.checkBudgets(ctx, updated)
+	return updated, nil
+}
+
+// updateInTx блокирует подписку, строит новое состояние через change, сводит название и категорию
+// к каталогу, применяет тариф, проверяет пересечение, записывает и пишет событие update в журнал.
+// Вызывается внутри InTx; возвращает записанную подписку, а при ошибке — состояние, которое пытались записать.
+func updateInTx(ctx context.Context, repo SubscriptionRepository, id int, change func(current model.Subscription) (model.Subscription, error)) (model.Subscription, error) {
+	// Сначала блокируем подписку: на несуществующий id надо ответить 404, а не конфликтом.
+	current, err := repo.LockSubscription(ctx, id, false)
+	if err != nil {
+		return model.Subscription{}, err
+	}
+	sub, err := change(current)
+	if err != nil {
 		return sub, err
 	}
+	sub.ID = id
+	sub.Version = current.Version
+	if err := resolveService(ctx, repo, &sub); err != nil {
+		return sub, err
+	}
+	if err := applyPlan(ctx, repo, &sub); err != nil {
+		return sub, err
+	}
+	if err := checkOverlap(ctx, repo, sub); err != nil {
+		return sub, err
+	}
+	updated, err := repo.UpdateSubscription(ctx, sub)
+	if err != nil {
+		return sub, err
+	}
+	return updated, recordEvent(ctx, repo, model.EventUpdate, id, &current, &updated)
+}
 
-	return sub, nil
+// normalizeSubscription подставляет значения по умолчанию для необязательных полей.
+func normalizeSubscription(sub model.Subscription) model.Subscription {
+	sub.BillingPeriod = sub.BillingPeriod.OrDefault()
+	sub.Currency = strings.ToUpper(strings.TrimSpace(sub.Currency))
+	if sub.Currency == "" && !sub.PriceFromPlan {
+		sub.Currency = model.DefaultCurrency
+	}
+	sub.Category = strings.ToLower(strings.TrimSpace(sub.Category))
+	sub.Tags = normalizeTags(sub.Tags)
+	return sub
 }
 
-func (s *SubscriptionSvc) GetSubscription(ctx context.Context, id int) (model.Subscription, error) {
-	return s.repo.GetSubscription(ctx, id)
+// normalizeTags приводит метки к нижнему регистру без пробелов по краям, убирает повторы
+// и упорядочивает. Пустые метки остаются, чтобы их отклонила проверка.
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		res = append(res, strings.ToLower(strings.TrimSpace(tag)))
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
 }
 
-func (s *SubscriptionSvc) UpdateSubscription(ctx context.Context, sub model.Subscription) error {
-	// по хорошему на этом этапе нужно проверять, чтобы подписка не пересекалась с другой от этого же пользователя
-	// и сервиса
+// validateSubscription проверяет поля подписки и собирает все ошибки сразу.
+func validateSubscription(sub model.Subscription) error {
+	fields := make(map[string]string)
+	if strings.TrimSpace(sub.ServiceName) == "" {
+		fields["service_name"] = "is required"
+	}
+	validateAmount(fields, "price_minor", sub.Price)
+	if err := uuid.Validate(sub.UserID); err != nil {
+		fields["user_id"] = "must be a UUID"
+	}
+	if sub.StartDate.IsZero() {
+		fields["start_date"] = "is required"
+	}
+	if sub.EndDate != nil && sub.EndDate.Before(sub.StartDate) {
+		fields["end_date"] = "cannot be before start_date"
+	}
+	if err := sub.BillingPeriod.Validate(); err != nil {
+		fields["billing_period"] = err.Error()
+	}
+	// Пустую валюту у подписки с ценой из тарифа заполнит applyPlan.
+	if !model.CurrencySupported(sub.Currency) && (sub.Currency != "" || !sub.PriceFromPlan) {
+		fields["currency"] = "unsupported currency"
+	}
+	if sub.Trial != nil {
+		if err := sub.Trial.Validate(); err != nil {
+			fields["trial"] = err.Error()
+		}
+	}
+	if sub.Intro != nil {
+		if err := sub.Intro.Validate(sub.BillingPeriod); err != nil {
+			fields["intro_price"] = err.Error()
+		}
+	}
+	if utf8.RuneCountInString(sub.Category) > model.MaxCategoryLength {
+		fields["category"] = fmt.Sprintf("must be at most %d characters", model.MaxCategoryLength)
+	}
+	if err := validateTags(sub.Tags); err != nil {
+		fields["tags"] = err.Error()
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
 
-	return s.repo.UpdateSubscription(ctx, sub)
+// validateAmount проверяет сумму в минимальных единицах: от 0 до model.MaxAmountMinor.
+func validateAmount(fields map[string]string, field string, amount int) {
+	switch {
+	case amount < 0:
+		fields[field] = "cannot be negative"
+	case amount > model.MaxAmountMinor:
+		fields[field] = fmt.Sprintf("must be at most %d", model.MaxAmountMinor)
+	}
 }
 
-func (s *SubscriptionSvc) DeleteSubscription(ctx context.Context, id int) error {
-	return s.repo.DeleteSubscription(ctx, id)
+// validateTags проверяет нормализованные метки. Запятая запрещена: через неё метки
+// перечисляются в фильтре tag.
+func validateTags(tags []string) error {
+	if len(tags) > model.MaxTags {
+		return fmt.Errorf("at most %d tags allowed", model.MaxTags)
+	}
+	for _, tag := range tags {
+		switch {
+		case tag == "":
+			return errors.New("tag cannot be empty")
+		case strings.Contains(tag, ","):
+			return fmt.Errorf("tag %q cannot contain a comma", tag)
+		case utf8.RuneCountInString(tag) > model.MaxTagLength:
+			return fmt.Errorf("tag %q is longer than %d characters", tag, model.MaxTagLength)
+		}
+	}
+	return nil
 }
 
-func (s *SubscriptionSvc) Sum(ctx context.Context, userID, serviceName string, startPeriod, endPeriod time.Time) (int, error) {
-	return s.repo.Sum(ctx, userID, serviceName, startPeriod, endPeriod)
+// checkOverlap — быстрая проверка до записи, чтобы вернуть клиенту id конфликтующей подписки.
+// Гонку двух параллельных запросов закрывает ограничение в БД, см. resolveOverlap.
+func checkOverlap(ctx context.Context, repo SubscriptionRepository, sub model.Subscription) error {
+	id, err := repo.FindOverlappingSubscription(ctx, sub)
+	if err != nil {
+		return err
+	}
+	if id != 0 {
+		return &OverlapError{ConflictingID: id}
+	}
+	return nil
 }
 
-func (s *SubscriptionSvc) ListSubscriptions(ctx context.Context, userID, serviceName string) ([]*model.Subscription, error) {
-	return s.repo.ListSubscriptions(ctx, userID, serviceName)
+// resolveOverlap дополняет ошибку ограничения БД id конфликтующей подписки.
+func (s *SubscriptionSvc) resolveOverlap(ctx context.Context, sub model.Subscription, err error) error {
+	var overlap *OverlapError
+	if !errors.As(err, &overlap) || overlap.ConflictingID != 0 {
+		return err
+	}
+	if id, ferr := s.repo.FindOverlappingSubscription(ctx, sub); ferr == nil {
+		overlap.ConflictingID = id
+	}
+	return err
+}
+
+// DeleteSubscription мягко удаляет подписку и пишет событие delete в журнал в той же транзакции.
+// Текущая версия должна подходить к ifMatch.
+func (s *SubscriptionSvc) DeleteSubscription(ctx context.Context, id int, ifMatch model.IfMatch) error {
+	return s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		current, err := repo.LockSubscription(ctx, id, false)
+		if err != nil {
+			return err
+		}
+		if !ifMatch.Matches(current.Version) {
+			return ErrPreconditionFailed
+		}
+		if err := repo.DeleteSubscription(ctx, id, current.Version); err != nil {
+			return err
+		}
+		deleted, err := repo.GetSubscription(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, repo, model.EventDelete, id, &current, &deleted)
+	})
+}
+
+// RestoreSubscription возвращает мягко удалённую подписку. Восстановление уже действующей
+// подписки ничего не меняет. Название и категория сводятся к каталогу: пока подписка была
+// удалена, сервис могли переименовать. Если за время удаления на тот же период завели другую
+// подписку, возвращается OverlapError.
+func (s *SubscriptionSvc) RestoreSubscription(ctx context.Context, id int) (model.Subscription, error) {
+	const op = "internal.service.RestoreSubscription"
+	log := s.logger.With(slog.String("op", op))
+
+	var resolved, restored model.Subscription
+	err := s.repo.InTx(ctx, func(repo SubscriptionRepository) error {
+		current, err := repo.LockSubscription(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		if current.DeletedAt == nil {
+			restored = current
+			return nil
+		}
+		resolved = current
+		if err := resolveService(ctx, repo, &resolved); err != nil {
+			return err
+		}
+		if err := checkOverlap(ctx, repo, resolved); err != nil {
+			return err
+		}
+		if restored, err = repo.RestoreSubscription(ctx, id); err != nil {
+			return err
+		}
+		if resolved.ServiceName != restored.ServiceName || resolved.Category != restored.Category {
+			restored.ServiceName, restored.Category = resolved.ServiceName, resolved.Category
+			if restored, err = repo.UpdateSubscription(ctx, restored); err != nil {
+				return err
+			}
+		}
+		return recordEvent(ctx, repo, model.EventRestore, id, &current, &restored)
+	})
+	if err != nil {
+		err = s.resolveOverlap(ctx, resolved, err)
+		log.Error("Can`t restore subscription", slog.String("error", err.Error()))
+		return model.Subscription{}, err
+	}
+	return restored, nil
+}
+
+// SubscriptionHistory возвращает журнал изменений подписки. История остаётся и после
+// удаления подписки; ErrNotFound — только если нет ни подписки, ни записей о ней.
+func (s *SubscriptionSvc) SubscriptionHistory(ctx context.Context, id int) (model.SubscriptionHistory, error) {
+	events, err := s.repo.ListSubscriptionEvents(ctx, id)
+	if err != nil {
+		return model.SubscriptionHistory{}, err
+	}
+	if len(events) == 0 {
+		// Подписки, созданные до появления журнала, существуют, но истории у них нет.
+		if _, err := s.repo.GetSubscription(ctx, id, true); err != nil {
+			return model.SubscriptionHistory{}, err
+		}
+		events = []model.SubscriptionEvent{}
+	}
+	return model.SubscriptionHistory{Items: events}, nil
+}
+
+// RunPurger раз в interval окончательно удаляет подписки, удалённые раньше, чем
+// soft_delete.retention назад, пока не отменён ctx. Нулевой срок хранения или
+// interval <= 0 отключают очистку.
+func (s *SubscriptionSvc) RunPurger(ctx context.Context, interval time.Duration) {
+	const op = "internal.service.SubscriptionPurger"
+	log := s.logger.With(slog.String("op", op))
+
+	retention := s.config.SoftDelete.Retention
+	if retention <= 0 || interval <= 0 {
+		log.Warn("purge of deleted subscriptions disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.repo.PurgeDeletedSubscriptions(ctx, time.Now().UTC().Add(-retention))
+			if err != nil {
+				log.Error("Can`t purge deleted subscriptions", slog.String("error", err.Error()))
+				continue
+			}
+			if n > 0 {
+				log.Info("deleted subscriptions purged", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+// ListSubscriptions отдаёт одну страницу списка. Лимит приводится к [1, model.MaxPageLimit],
+// NextCursor пустой, если дальше записей нет.
+func (s *SubscriptionSvc) ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, page model.Page) (model.SubscriptionList, error) {
+	switch {
+	case page.Limit <= 0:
+		page.Limit = model.DefaultPageLimit
+	case page.Limit > model.MaxPageLimit:
+		page.Limit = model.MaxPageLimit
+	}
+	if page.Sort == "" {
+		page.Sort = model.SortByID
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница.
+	limit := page.Limit
+	page.Limit++
+	subs, err := s.repo.ListSubscriptions(ctx, filter, page)
+	if err != nil {
+		return model.SubscriptionList{}, err
+	}
+
+	list := model.SubscriptionList{Items: subs}
+	if len(subs) > limit {
+		list.Items = subs[:limit]
+		list.NextCursor = page.NextCursor(list.Items[limit-1]).Encode()
+	}
+	if list.Items == nil {
+		list.Items = []*model.Subscription{}
+	}
+	return list, nil
 }
 
 func (s *SubscriptionSvc) Ping(ctx context.Context) error {
 	return s.repo.Ping(ctx)
 }
-
-// проверяем имплиментацию
-var _ SubscriptionRepository = (*postgres.Storage)(nil)
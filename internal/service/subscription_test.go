@@ -0,0 +1,33 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"subscription/internal/model"
+	"subscription/internal/service"
+	"testing"
+)
+
+func TestCreateSubscriptionPriceLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     model.Subscription
+		wantErr bool
+	}{
+		{name: "at the limit", sub: model.Subscription{Price: model.MaxAmountMinor}},
+		{name: "above the limit", sub: model.Subscription{Price: model.MaxAmountMinor + 1}, wantErr: true},
+		{name: "negative", sub: model.Subscription{Price: -1}, wantErr: true},
+		{name: "intro above the limit", sub: model.Subscription{Price: 100,
+			Intro: &model.IntroPrice{Price: model.MaxAmountMinor + 1, Periods: 1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newMemoryService(t)
+			tt.sub.ServiceName, tt.sub.UserID, tt.sub.StartDate = "Netflix", userA, month(t, "01-2025")
+			_, err := svc.CreateSubscription(context.Background(), tt.sub)
+			if got := errors.Is(err, service.ErrValidation); got != tt.wantErr {
+				t.Errorf("err = %v, want validation error: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
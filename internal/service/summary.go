@@ -0,0 +1,522 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"subscription/internal/model"
+	"time"
+)
+
+// sumBatchSize — по сколько подписок Sum загружает, когда считает их по одной.
+const sumBatchSize = model.MaxPageLimit
+
+// Sum считает стоимость подписок за период [startPeriod, endPeriod] (границы — месяцы, включительно,
+// не больше model.MaxSummaryMonths).
+// В режиме accrual цена подписки приводится к месяцу и умножается на количество месяцев, в которые
+// она была активна внутри периода; в режиме cash складываются списания, даты которых попали в период.
+// Для подписок с графиком цен берётся цена, действовавшая в месяце (на дату списания);
+// пробный период не стоит ничего, в период вводной цены берётся она.
+// Суммы в других валютах пересчитываются в opts.Currency по курсу на первое число месяца.
+// Если передан opts.GroupBy, итог дополнительно раскладывается по корзинам.
+//
+// В режиме accrual без opts.IncludeItems подписки без поправок агрегирует хранилище
+// (SumSubscriptions), в сервис по одной загружаются только остальные — порциями по sumBatchSize.
+func (s *SubscriptionSvc) Sum(ctx context.Context, filter model.SubscriptionFilter, startPeriod, endPeriod time.Time, opts model.SummaryOptions) (model.Summary, error) {
+	const op = "internal.service.Sum"
+	log := s.logger.With(slog.String("op", op))
+
+	if endPeriod.Before(startPeriod) {
+		return model.Summary{}, NewValidationError("to", "cannot be before from")
+	}
+	if model.MonthsBetween(startPeriod, endPeriod) > model.MaxSummaryMonths {
+		return model.Summary{}, NewValidationError("to", fmt.Sprintf("must be within %d months of from", model.MaxSummaryMonths))
+	}
+	if opts.Currency != "" && !model.CurrencySupported(opts.Currency) {
+		return model.Summary{}, NewValidationError("currency", "unsupported currency")
+	}
+	// Период задают startPeriod и endPeriod; свой период фильтра был бы молча заменён.
+	if filter.ActiveWithin != nil {
+		return model.Summary{}, NewValidationError("active_between", "is not supported by summary, use from and to")
+	}
+
+	acc := &summaryAcc{
+		svc:      s,
+		opts:     opts,
+		currency: opts.Currency,
+		groups:   newGrouper(opts.GroupBy),
+		adjusted: make(map[string]bool),
+	}
+	filter.ActiveWithin = &model.Period{From: startPeriod, To: endPeriod}
+	if opts.Mode != model.SumCash && !opts.IncludeItems {
+		if err := acc.addAggregate(ctx, filter, startPeriod, endPeriod); err != nil {
+			log.Error("Can`t sum subscriptions", slog.String("error", err.Error()))
+			return model.Summary{}, err
+		}
+		adjusted := true
+		filter.Adjusted = &adjusted
+	}
+
+	page := model.Page{Limit: sumBatchSize, Sort: model.SortByID}
+	for {
+		subs, err := s.repo.ListSubscriptions(ctx, filter, page)
+		if err != nil {
+			log.Error("Can`t list subscriptions", slog.String("error", err.Error()))
+			return model.Summary{}, err
+		}
+		if err := acc.addSubscriptions(ctx, subs, startPeriod, endPeriod); err != nil {
+			log.Error("Can`t sum subscriptions", slog.String("error", err.Error()))
+			return model.Summary{}, err
+		}
+		if len(subs) < page.Limit {
+			break
+		}
+		cursor := page.NextCursor(subs[len(subs)-1])
+		page.After = &cursor
+	}
+
+	return acc.result(startPeriod, endPeriod), nil
+}
+
+// summaryAcc накапливает итог Sum по порциям подписок и строкам агрегата хранилища.
+type summaryAcc struct {
+	svc      *SubscriptionSvc
+	opts     model.SummaryOptions
+	currency string // валюта итога; без opts.Currency — валюта первой встреченной подписки
+	conv     *fxConverter
+	total    float64
+	groups   *grouper
+	adjusted map[string]bool
+	items    []model.SummaryItem
+	// truncated — подписок больше, чем помещается в Items.
+	truncated bool
+}
+
+// useCurrency проверяет, что без запрошенной валюты все подписки в одной валюте.
+func (a *summaryAcc) useCurrency(currency string) error {
+	if a.currency == "" {
+		a.currency = currency
+	}
+	if a.opts.Currency == "" && currency != a.currency {
+		return NewValidationError("currency", "is required when subscriptions are in different currencies")
+	}
+	return nil
+}
+
+// convert переводит сумму в валюту итога; курсы загружаются при первом пересчёте.
+func (a *summaryAcc) convert(ctx context.Context, amount float64, currency string, month time.Time) (float64, error) {
+	if currency == a.currency {
+		return amount, nil
+	}
+	a.adjusted[model.AdjustmentFX] = true
+	if a.conv == nil {
+		rates, err := a.svc.repo.ListFXRates(ctx)
+		if err != nil {
+			return 0, err
+		}
+		a.conv = newFXConverter(rates)
+	}
+	return a.conv.convert(amount, currency, a.currency, month)
+}
+
+// addAggregate добавляет подписки без поправок, посчитанные хранилищем. Если валюта итога
+// запрошена, строки дополнительно разбиваются по месяцам, чтобы пересчитать их по курсу месяца.
+// При группировке по метке итог берётся из отдельного запроса без неё: иначе подписка с
+// несколькими метками вошла бы в него несколько раз.
+func (a *summaryAcc) addAggregate(ctx context.Context, filter model.SubscriptionFilter, from, to time.Time) error {
+	q := model.SumQuery{Filter: filter, From: from, To: to, GroupBy: slices.Clone(a.opts.GroupBy)}
+	if a.opts.Currency != "" && !slices.Contains(q.GroupBy, model.GroupByMonth) {
+		q.GroupBy = append(q.GroupBy, model.GroupByMonth)
+	}
+	rows, err := a.svc.repo.SumSubscriptions(ctx, q)
+	if err != nil {
+		return err
+	}
+	byTag := slices.Contains(q.GroupBy, model.GroupByTag)
+	for _, row := range rows {
+		if err := a.useCurrency(row.Currency); err != nil {
+			return err
+		}
+		cost, err := a.convert(ctx, row.Cost, row.Currency, row.Month)
+		if err != nil {
+			return err
+		}
+		a.groups.addRow(row, cost)
+		if !byTag {
+			a.total += cost
+		}
+	}
+	if !byTag {
+		return nil
+	}
+
+	q.GroupBy = slices.DeleteFunc(q.GroupBy, func(g model.GroupBy) bool { return g != model.GroupByMonth })
+	totals, err := a.svc.repo.SumSubscriptions(ctx, q)
+	if err != nil {
+		return err
+	}
+	for _, row := range totals {
+		cost, err := a.convert(ctx, row.Cost, row.Currency, row.Month)
+		if err != nil {
+			return err
+		}
+		a.total += cost
+	}
+	return nil
+}
+
+// addSubscriptions считает подписки по одной: по месяцам с учётом поправок или по списаниям.
+func (a *summaryAcc) addSubscriptions(ctx context.Context, subs []*model.Subscription, from, to time.Time) error {
+	if len(subs) == 0 {
+		return nil
+	}
+	schedules, err := a.svc.priceSchedules(ctx, subs)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		months := sub.ActiveMonths(from, to)
+		if months == 0 {
+			continue
+		}
+		if err := a.useCurrency(sub.Currency); err != nil {
+			return err
+		}
+		prices := schedules[sub.ID]
+		if sub.Trial != nil {
+			a.adjusted[model.AdjustmentTrial] = true
+		}
+		if sub.Intro != nil {
+			a.adjusted[model.AdjustmentIntroPrice] = true
+		}
+		if len(prices) > 0 {
+			a.adjusted[model.AdjustmentPriceChanges] = true
+		}
+
+		costs, charges := monthlyCosts(*sub, prices, from, to, a.opts.Mode)
+		var cost float64
+		for m, c := range costs {
+			if costs[m], err = a.convert(ctx, c, sub.Currency, model.MonthFromIndex(m)); err != nil {
+				return err
+			}
+			cost += costs[m]
+		}
+		a.total += cost
+		a.groups.add(sub, costs)
+
+		if !a.opts.IncludeItems {
+			continue
+		}
+		if len(a.items) == model.MaxPageLimit {
+			a.truncated = true
+			continue
+		}
+		a.items = append(a.items, model.SummaryItem{
+			SubscriptionID: sub.ID,
+			ServiceName:    sub.ServiceName,
+			UserID:         sub.UserID,
+			PlanID:         sub.PlanID,
+			Category:       sub.Category,
+			Tags:           sub.Tags,
+			Price:          prices.PriceAt(sub.Price, lastActiveMonth(*sub, to)),
+			Currency:       sub.Currency,
+			Months:         months,
+			Charges:        charges,
+			Cost:           int(math.Round(cost)),
+		})
+	}
+	return nil
+}
+
+// result округляет итог и собирает Summary.
+func (a *summaryAcc) result(from, to time.Time) model.Summary {
+	summary := model.Summary{
+		Total:          int(math.Round(a.total)),
+		Currency:       cmp.Or(a.currency, model.DefaultCurrency),
+		Months:         model.MonthsBetween(from, to),
+		Method:         model.SumMethodAccrual,
+		Items:          a.items,
+		ItemsTruncated: a.truncated,
+		Groups:         a.groups.result(),
+	}
+	if a.opts.Mode == model.SumCash {
+		summary.Method = model.SumMethodCharges
+	}
+	for _, adj := range []string{model.AdjustmentFX, model.AdjustmentTrial, model.AdjustmentIntroPrice, model.AdjustmentPriceChanges} {
+		if a.adjusted[adj] {
+			summary.Adjustments = append(summary.Adjustments, adj)
+		}
+	}
+	return summary
+}
+
+// summaryCurrency выбирает валюту итога: запрошенную, а без неё — общую валюту подписок.
+func summaryCurrency(subs []*model.Subscription, requested string) (string, error) {
+	if requested != "" {
+		if !model.CurrencySupported(requested) {
+			return "", NewValidationError("currency", "unsupported currency")
+		}
+		return requested, nil
+	}
+	currency := model.DefaultCurrency
+	for i, sub := range subs {
+		if i == 0 {
+			currency = sub.Currency
+		} else if sub.Currency != currency {
+			return "", NewValidationError("currency", "is required when subscriptions are in different currencies")
+		}
+	}
+	return currency, nil
+}
+
+// fxConverter загружает курсы, только если какую-то подписку придётся пересчитывать.
+func (s *SubscriptionSvc) fxConverter(ctx context.Context, subs []*model.Subscription, currency string) (*fxConverter, error) {
+	for _, sub := range subs {
+		if sub.Currency != currency {
+			rates, err := s.repo.ListFXRates(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return newFXConverter(rates), nil
+		}
+	}
+	return newFXConverter(nil), nil
+}
+
+// monthlyCosts раскладывает стоимость подписки по месяцам периода [from, to] (ключ — model.MonthIndex).
+// Пробный период бесплатен, вводная цена действует первые Intro.Periods периодов после него.
+// В режиме cash возвращает и число списаний.
+func monthlyCosts(sub model.Subscription, prices model.PriceSchedule, from, to time.Time, mode model.SumMode) (map[int]float64, int) {
+	billing := sub.BillingPeriod.OrDefault()
+	paidFrom, introUntil := sub.PaidFrom(), sub.IntroUntil()
+	costs := make(map[int]float64)
+
+	if mode != model.SumCash {
+		// Месяц, который пробный период или вводная цена покрывают частично, считается
+		// пропорционально дням: например, 14 дней пробного периода с 1 января — 17/31 цены января.
+		share := billing.MonthlyShare()
+		for m := model.MonthIndex(from); m <= model.MonthIndex(to); m++ {
+			month := model.MonthFromIndex(m)
+			if sub.ActiveMonths(month, month) == 0 {
+				continue
+			}
+			next := model.MonthFromIndex(m + 1)
+			regular := monthShare(month, next, later(paidFrom, introUntil), next)
+			cost := float64(prices.PriceAt(sub.Price, month)) * regular
+			if sub.Intro != nil {
+				cost += float64(sub.Intro.Price) * monthShare(month, next, paidFrom, introUntil)
+			}
+			costs[m] += cost * share
+		}
+		return costs, 0
+	}
+
+	charges := subscriptionCharges(sub, prices, from, to)
+	for _, c := range charges {
+		costs[model.MonthIndex(c.at)] += float64(c.price)
+	}
+	return costs, len(charges)
+}
+
+// charge — одно списание по подписке.
+type charge struct {
+	at    time.Time
+	price int
+	intro bool
+}
+
+// subscriptionCharges перечисляет списания подписки, даты которых попали в месяцы [from, to].
+// Списания идут от конца пробного периода с шагом периода, пока подписка активна: end_date —
+// включительно, то есть до конца месяца окончания.
+func subscriptionCharges(sub model.Subscription, prices model.PriceSchedule, from, to time.Time) []charge {
+	billing := sub.BillingPeriod.OrDefault()
+	paidFrom := sub.PaidFrom()
+	until := model.MonthIndex(lastActiveMonth(sub, to))
+	var charges []charge
+	for n := 0; ; n++ {
+		at := billing.Add(paidFrom, n)
+		m := model.MonthIndex(at)
+		if m > until {
+			break
+		}
+		if m < model.MonthIndex(from) {
+			continue
+		}
+		c := charge{at: at, price: prices.PriceAt(sub.Price, at)}
+		if sub.Intro != nil && n < sub.Intro.Periods {
+			c.price, c.intro = sub.Intro.Price, true
+		}
+		charges = append(charges, c)
+	}
+	return charges
+}
+
+// monthShare — доля месяца [month, next), которую покрывает интервал [from, to).
+func monthShare(month, next, from, to time.Time) float64 {
+	from, to = later(month, from), earlier(next, to)
+	if !from.Before(to) {
+		return 0
+	}
+	return to.Sub(from).Hours() / next.Sub(month).Hours()
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// lastActiveMonth — последний месяц периода, в который подписка ещё активна.
+func lastActiveMonth(sub model.Subscription, to time.Time) time.Time {
+	if sub.EndDate != nil && sub.EndDate.Before(to) {
+		return *sub.EndDate
+	}
+	return to
+}
+
+// groupKey — ключ корзины; незадействованные измерения остаются нулевыми.
+type groupKey struct {
+	month       int // model.MonthIndex, -1 если группировки по месяцу нет
+	serviceName string
+	userID      string
+	planID      int // 0 — подписки без тарифа
+	category    string
+	tag         string
+}
+
+type grouper struct {
+	byMonth, byService, byUser, byPlan, byCategory, byTag bool
+	totals                                                map[groupKey]float64
+}
+
+func newGrouper(groupBy []model.GroupBy) *grouper {
+	if len(groupBy) == 0 {
+		return nil
+	}
+	g := &grouper{totals: make(map[groupKey]float64)}
+	for _, dim := range groupBy {
+		switch dim {
+		case model.GroupByMonth:
+			g.byMonth = true
+		case model.GroupByServiceName:
+			g.byService = true
+		case model.GroupByUserID:
+			g.byUser = true
+		case model.GroupByPlanID:
+			g.byPlan = true
+		case model.GroupByCategory:
+			g.byCategory = true
+		case model.GroupByTag:
+			g.byTag = true
+		}
+	}
+	return g
+}
+
+// add раскладывает помесячную стоимость подписки (см. monthlyCosts) по корзинам.
+// При группировке по метке стоимость целиком идёт в корзину каждой метки подписки.
+func (g *grouper) add(sub *model.Subscription, costs map[int]float64) {
+	if g == nil {
+		return
+	}
+	key := groupKey{month: -1}
+	if g.byService {
+		key.serviceName = sub.ServiceName
+	}
+	if g.byUser {
+		key.userID = sub.UserID
+	}
+	if g.byPlan && sub.PlanID != nil {
+		key.planID = *sub.PlanID
+	}
+	if g.byCategory {
+		key.category = sub.Category
+	}
+	tags := []string{""}
+	if g.byTag && len(sub.Tags) > 0 {
+		tags = sub.Tags
+	}
+	for _, tag := range tags {
+		key.tag = tag
+		for m, cost := range costs {
+			if g.byMonth {
+				key.month = m
+			}
+			g.totals[key] += cost
+		}
+	}
+}
+
+// addRow добавляет строку агрегата хранилища, уже пересчитанную в валюту итога (cost).
+// Лишняя разбивка строки по месяцу, нужная для пересчёта, здесь отбрасывается.
+func (g *grouper) addRow(row model.SumRow, cost float64) {
+	if g == nil {
+		return
+	}
+	key := groupKey{
+		month:       -1,
+		serviceName: row.ServiceName,
+		userID:      row.UserID,
+		category:    row.Category,
+		tag:         row.Tag,
+	}
+	if g.byMonth {
+		key.month = model.MonthIndex(row.Month)
+	}
+	if row.PlanID != nil {
+		key.planID = *row.PlanID
+	}
+	g.totals[key] += cost
+}
+
+// result округляет корзины до целого; из-за округления их сумма может на единицы
+// расходиться с Total.
+func (g *grouper) result() []model.SummaryGroup {
+	if g == nil {
+		return nil
+	}
+	keys := make([]groupKey, 0, len(g.totals))
+	for k := range g.totals {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b groupKey) int {
+		return cmp.Or(
+			cmp.Compare(a.month, b.month),
+			cmp.Compare(a.serviceName, b.serviceName),
+			cmp.Compare(a.userID, b.userID),
+			cmp.Compare(a.planID, b.planID),
+			cmp.Compare(a.category, b.category),
+			cmp.Compare(a.tag, b.tag),
+		)
+	})
+
+	res := make([]model.SummaryGroup, 0, len(keys))
+	for _, k := range keys {
+		grp := model.SummaryGroup{
+			ServiceName: k.serviceName,
+			UserID:      k.userID,
+			Category:    k.category,
+			Tag:         k.tag,
+			Total:       int(math.Round(g.totals[k])),
+		}
+		if k.month >= 0 {
+			grp.Month = model.MonthFromIndex(k.month).Format(model.MonthLayout)
+		}
+		if k.planID != 0 {
+			grp.PlanID = &k.planID
+		}
+		res = append(res, grp)
+	}
+	return res
+}
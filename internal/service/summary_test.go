@@ -0,0 +1,278 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"reflect"
+	"subscription/internal/config"
+	"subscription/internal/model"
+	"subscription/internal/repository/memory"
+	"subscription/internal/repository/sqlite"
+	"subscription/internal/service"
+	"subscription/migrations"
+	"testing"
+	"time"
+)
+
+const (
+	userA = "123e4567-e89b-12d3-a456-426614174000"
+	userB = "223e4567-e89b-12d3-a456-426614174000"
+)
+
+func month(t *testing.T, s string) time.Time {
+	t.Helper()
+	m, err := time.Parse(model.MonthLayout, s)
+	if err != nil {
+		t.Fatalf("parse month %q: %v", s, err)
+	}
+	return m
+}
+
+// newMemoryService — сервис поверх хранилища в памяти.
+func newMemoryService(t *testing.T) *service.SubscriptionSvc {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return service.NewSubscriptionService(memory.New(), logger, &config.Config{})
+}
+
+// newSQLiteService — сервис поверх SQLite во временном файле с применёнными миграциями.
+func newSQLiteService(t *testing.T) *service.SubscriptionSvc {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{Database: config.Database{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "subscriptions.db"),
+	}}
+	if err := migrations.RunMigrations(cfg, logger); err != nil {
+		t.Fatalf("migrations: %v", err)
+	}
+
+	repo, err := sqlite.NewSQLiteDB(cfg)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+	return service.NewSubscriptionService(repo, logger, cfg)
+}
+
+// TestSumAggregateMatchesItems проверяет, что подписки без поправок хранилище считает так же,
+// как сервис по одной: без include=items итог даёт SumSubscriptions, с ним — monthlyCosts.
+// Оба результата сверяются с посчитанными вручную.
+func TestSumAggregateMatchesItems(t *testing.T) {
+	// Доли за месяц внутри 01-2025..06-2025:
+	//   monthly A — 1000 с 11-2024 по 04-2025: 4 месяца;
+	//   weekly A — 250 в неделю с 02-2025: 250 × 365.25/7/12 = 1087.05… за месяц, 5 месяцев;
+	//   quarterly B — 3100 в квартал: 1033.33… за месяц, 6 месяцев;
+	//   biennial B — 9600 за 2 года: 400 за месяц, 4 месяца;
+	//   monthly B — 15 USD за 3 месяца: 5 USD за месяц, по 90.5 в январе–марте и 97.25 в апреле–июне.
+	const (
+		weekly  = 250 * 365.25 / 7 / 12
+		monthly = 4 * 1000
+		usd     = 3*5*90.5 + 3*5*97.25
+	)
+	round := func(v float64) int { return int(math.Round(v)) }
+	repos := map[string]func(*testing.T) *service.SubscriptionSvc{
+		"memory": newMemoryService,
+		"sqlite": newSQLiteService,
+	}
+	for name, newSvc := range repos {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			svc := newSvc(t)
+
+			end := month(t, "04-2025")
+			subs := []model.Subscription{
+				// Начинается до периода и заканчивается внутри: активны только часть месяцев.
+				{ServiceName: "monthly", Price: 1000, UserID: userA, StartDate: month(t, "11-2024"), EndDate: &end,
+					Category: "entertainment", Tags: []string{"family"}},
+				{ServiceName: "weekly", Price: 250, UserID: userA, StartDate: month(t, "02-2025"),
+					BillingPeriod: model.BillingPeriod{Unit: model.BillingWeek, Count: 1}, Tags: []string{"work", "family"}},
+				{ServiceName: "quarterly", Price: 3100, UserID: userB, StartDate: month(t, "12-2024"),
+					BillingPeriod: model.BillingPeriod{Unit: model.BillingQuarter, Count: 1}, Category: "productivity"},
+				{ServiceName: "biennial", Price: 9600, UserID: userB, StartDate: month(t, "03-2025"),
+					BillingPeriod: model.BillingPeriod{Unit: model.BillingYear, Count: 2}},
+				{ServiceName: "monthly", Price: 15, Currency: "USD", UserID: userB, StartDate: month(t, "01-2025"),
+					BillingPeriod: model.BillingPeriod{Unit: model.BillingMonth, Count: 3}, Tags: []string{"work"}},
+			}
+			for _, sub := range subs {
+				if _, err := svc.CreateSubscription(ctx, sub); err != nil {
+					t.Fatalf("create %+v: %v", sub, err)
+				}
+			}
+			for _, rate := range []model.FXRate{
+				{Base: "USD", Quote: "RUB", Rate: 90.5, ValidFrom: month(t, "01-2025")},
+				{Base: "USD", Quote: "RUB", Rate: 97.25, ValidFrom: month(t, "04-2025")},
+			} {
+				if _, err := svc.SetFXRate(ctx, rate); err != nil {
+					t.Fatalf("set fx rate: %v", err)
+				}
+			}
+
+			total := round(monthly + 5*weekly + 6200 + 1600 + usd)
+			tests := []struct {
+				name       string
+				filter     model.SubscriptionFilter
+				groupBy    []model.GroupBy
+				wantTotal  int
+				wantGroups []model.SummaryGroup // nil — корзины сверяются только между путями
+			}{
+				{
+					name:      "total",
+					filter:    model.SubscriptionFilter{UserIDs: []string{userA}},
+					wantTotal: round(monthly + 5*weekly),
+				},
+				{
+					name:      "by month",
+					filter:    model.SubscriptionFilter{UserIDs: []string{userA}},
+					groupBy:   []model.GroupBy{model.GroupByMonth},
+					wantTotal: round(monthly + 5*weekly),
+					wantGroups: []model.SummaryGroup{
+						{Month: "01-2025", Total: 1000},
+						{Month: "02-2025", Total: round(1000 + weekly)},
+						{Month: "03-2025", Total: round(1000 + weekly)},
+						{Month: "04-2025", Total: round(1000 + weekly)},
+						{Month: "05-2025", Total: round(weekly)},
+						{Month: "06-2025", Total: round(weekly)},
+					},
+				},
+				{
+					name:      "by service and user",
+					groupBy:   []model.GroupBy{model.GroupByServiceName, model.GroupByUserID},
+					wantTotal: total,
+					wantGroups: []model.SummaryGroup{
+						{ServiceName: "biennial", UserID: userB, Total: 1600},
+						{ServiceName: "monthly", UserID: userA, Total: monthly},
+						{ServiceName: "monthly", UserID: userB, Total: round(usd)},
+						{ServiceName: "quarterly", UserID: userB, Total: 6200},
+						{ServiceName: "weekly", UserID: userA, Total: round(5 * weekly)},
+					},
+				},
+				{
+					name:      "by category",
+					groupBy:   []model.GroupBy{model.GroupByCategory},
+					wantTotal: total,
+					wantGroups: []model.SummaryGroup{
+						{Total: round(5*weekly + 1600 + usd)},
+						{Category: "entertainment", Total: monthly},
+						{Category: "productivity", Total: 6200},
+					},
+				},
+				{
+					name:      "by tag",
+					groupBy:   []model.GroupBy{model.GroupByTag},
+					wantTotal: total,
+					wantGroups: []model.SummaryGroup{
+						{Total: 6200 + 1600},
+						{Tag: "family", Total: round(monthly + 5*weekly)},
+						{Tag: "work", Total: round(5*weekly + usd)},
+					},
+				},
+				{
+					name:      "by tag and month",
+					groupBy:   []model.GroupBy{model.GroupByTag, model.GroupByMonth},
+					wantTotal: total,
+				},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					from, to := month(t, "01-2025"), month(t, "06-2025")
+					opts := model.SummaryOptions{GroupBy: tt.groupBy, Currency: "RUB"}
+					aggregate, err := svc.Sum(ctx, tt.filter, from, to, opts)
+					if err != nil {
+						t.Fatalf("sum: %v", err)
+					}
+					opts.IncludeItems = true
+					items, err := svc.Sum(ctx, tt.filter, from, to, opts)
+					if err != nil {
+						t.Fatalf("sum with items: %v", err)
+					}
+					if aggregate.Total != tt.wantTotal || items.Total != tt.wantTotal {
+						t.Errorf("total = %d, with items %d, want %d", aggregate.Total, items.Total, tt.wantTotal)
+					}
+					if !reflect.DeepEqual(aggregate.Groups, items.Groups) {
+						t.Errorf("groups = %+v,\nwith items %+v", aggregate.Groups, items.Groups)
+					}
+					if tt.wantGroups != nil && !reflect.DeepEqual(aggregate.Groups, tt.wantGroups) {
+						t.Errorf("groups = %+v,\nwant %+v", aggregate.Groups, tt.wantGroups)
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestSumCash проверяет режим cash по месяцам: каждое списание целиком идёт в месяц своей даты.
+func TestSumCash(t *testing.T) {
+	ctx := context.Background()
+	svc := newMemoryService(t)
+
+	end := month(t, "03-2025")
+	subs := []model.Subscription{
+		// Пробный период 30 дней: списания 31.01, 28.02, 31.03, 30.04, 31.05, 30.06 — по одному в месяц.
+		{ServiceName: "monthly", Price: 1000, UserID: userA, StartDate: month(t, "01-2025"),
+			Trial: &model.Trial{Unit: model.TrialDay, Count: 30}},
+		// end_date включительно: последнее списание в марте.
+		{ServiceName: "ending", Price: 100, UserID: userA, StartDate: month(t, "01-2025"), EndDate: &end},
+		{ServiceName: "yearly", Price: 12000, UserID: userA, StartDate: month(t, "03-2025"),
+			BillingPeriod: model.BillingPeriod{Unit: model.BillingYear, Count: 1}},
+		// С ноября: списания 01.02 и 01.05.
+		{ServiceName: "quarterly", Price: 3000, UserID: userA, StartDate: month(t, "11-2024"),
+			BillingPeriod: model.BillingPeriod{Unit: model.BillingQuarter, Count: 1}},
+		// С 1 февраля каждую субботу: 4, 5, 4, 5 и 4 списания в феврале–июне.
+		{ServiceName: "weekly", Price: 700, UserID: userA, StartDate: month(t, "02-2025"),
+			BillingPeriod: model.BillingPeriod{Unit: model.BillingWeek, Count: 1}},
+	}
+	for _, sub := range subs {
+		if _, err := svc.CreateSubscription(ctx, sub); err != nil {
+			t.Fatalf("create %+v: %v", sub, err)
+		}
+	}
+
+	summary, err := svc.Sum(ctx, model.SubscriptionFilter{UserIDs: []string{userA}}, month(t, "01-2025"), month(t, "06-2025"),
+		model.SummaryOptions{Mode: model.SumCash, GroupBy: []model.GroupBy{model.GroupByMonth}, IncludeItems: true})
+	if err != nil {
+		t.Fatalf("sum: %v", err)
+	}
+
+	wantGroups := []model.SummaryGroup{
+		{Month: "01-2025", Total: 1000 + 100},
+		{Month: "02-2025", Total: 1000 + 100 + 3000 + 4*700},
+		{Month: "03-2025", Total: 1000 + 100 + 12000 + 5*700},
+		{Month: "04-2025", Total: 1000 + 4*700},
+		{Month: "05-2025", Total: 1000 + 3000 + 5*700},
+		{Month: "06-2025", Total: 1000 + 4*700},
+	}
+	if !reflect.DeepEqual(summary.Groups, wantGroups) {
+		t.Errorf("groups = %+v,\nwant %+v", summary.Groups, wantGroups)
+	}
+	if want := 6*1000 + 3*100 + 12000 + 2*3000 + 22*700; summary.Total != want {
+		t.Errorf("total = %d, want %d", summary.Total, want)
+	}
+	if summary.Method != model.SumMethodCharges {
+		t.Errorf("method = %q, want %q", summary.Method, model.SumMethodCharges)
+	}
+
+	wantCharges := map[string]int{"monthly": 6, "ending": 3, "yearly": 1, "quarterly": 2, "weekly": 22}
+	for _, item := range summary.Items {
+		if item.Charges != wantCharges[item.ServiceName] {
+			t.Errorf("%s: charges = %d, want %d", item.ServiceName, item.Charges, wantCharges[item.ServiceName])
+		}
+	}
+}
+
+func TestSumPeriodLimit(t *testing.T) {
+	svc := newMemoryService(t)
+	from := month(t, "01-2015")
+	if _, err := svc.Sum(context.Background(), model.SubscriptionFilter{}, from, from.AddDate(0, model.MaxSummaryMonths-1, 0), model.SummaryOptions{}); err != nil {
+		t.Errorf("%d months: %v", model.MaxSummaryMonths, err)
+	}
+	_, err := svc.Sum(context.Background(), model.SubscriptionFilter{}, from, from.AddDate(0, model.MaxSummaryMonths, 0), model.SummaryOptions{})
+	if !errors.Is(err, service.ErrValidation) {
+		t.Errorf("%d months: err = %v, want validation error", model.MaxSummaryMonths+1, err)
+	}
+}
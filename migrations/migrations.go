@@ -1,30 +1,61 @@
 package migrations
 
 import (
+	"embed"
 	"fmt"
 	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/golang-migrate/migrate/v4/database/mysql"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"log/slog"
+	"net"
 	"subscription/internal/config"
 )
 
+// files — миграции всех драйверов, встроенные в бинарь: применение не зависит от рабочего каталога.
+//
+//go:embed postgres mysql sqlite
+var files embed.FS
+
+// RunMigrations применяет миграции из migrations/<driver>: у каждого драйвера свой диалект SQL.
 func RunMigrations(cfg *config.Config, logger *slog.Logger) error {
 
-	ps := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Name,
-		cfg.Database.SSLMode,
-	)
+	var dsn string
+	switch cfg.Database.Driver {
+	case config.DriverMemory:
+		logger.Info("migrations skipped", slog.String("driver", cfg.Database.Driver))
+		return nil
+	case config.DriverPostgres:
+		dsn = fmt.Sprintf(
+			"pgx5://%s:%s@%s:%s/%s?sslmode=%s",
+			cfg.Database.User,
+			cfg.Database.Password,
+			cfg.Database.Host,
+			cfg.Database.Port,
+			cfg.Database.Name,
+			cfg.Database.SSLMode,
+		)
+	case config.DriverMySQL:
+		// multiStatements нужен, чтобы выполнить файл миграции с несколькими запросами целиком
+		dsn = fmt.Sprintf(
+			"mysql://%s:%s@tcp(%s)/%s?multiStatements=true",
+			cfg.Database.User,
+			cfg.Database.Password,
+			net.JoinHostPort(cfg.Database.Host, cfg.Database.Port),
+			cfg.Database.Name,
+		)
+	case config.DriverSQLite:
+		dsn = "sqlite://" + cfg.Database.Path
+	default:
+		return fmt.Errorf("unsupported database driver: %q", cfg.Database.Driver)
+	}
 
-	m, err := migrate.New(
-		"file://migrations",
-		ps,
-	)
+	src, err := iofs.New(files, cfg.Database.Driver)
+	if err != nil {
+		return fmt.Errorf("open migrations: %w", err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, dsn)
 	if err != nil {
 		return fmt.Errorf("create migrate: %w", err)
 	}
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- service_name с бинарной коллацией: сравнение с учётом регистра, как в Postgres и SQLite.
+CREATE TABLE subscriptions (
+                               id INT AUTO_INCREMENT PRIMARY KEY,
+                               service_name VARCHAR(255) COLLATE utf8mb4_bin NOT NULL,
+                               price INT NOT NULL,
+                               user_id CHAR(36) NOT NULL,
+                               start_date DATE NOT NULL,
+                               end_date DATE NULL
+);
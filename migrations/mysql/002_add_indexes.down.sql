@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX idx_subscriptions_user ON subscriptions;
+DROP INDEX idx_subscriptions_service ON subscriptions;
+DROP INDEX idx_subscriptions_period ON subscriptions;
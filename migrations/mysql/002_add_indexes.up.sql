@@ -0,0 +1,4 @@
+-- +migrate Up
+CREATE INDEX idx_subscriptions_user ON subscriptions (user_id);
+CREATE INDEX idx_subscriptions_service ON subscriptions (service_name);
+CREATE INDEX idx_subscriptions_period ON subscriptions (start_date, end_date);
@@ -0,0 +1,5 @@
+-- +migrate Up
+INSERT INTO subscriptions (user_id, service_name, price, start_date)
+VALUES
+    ('11111111-1111-1111-1111-111111111111', 'netflix', 800, DATE_FORMAT(NOW(), '%Y-%m-01') - INTERVAL 2 MONTH),
+    ('22222222-2222-2222-2222-222222222222', 'spotify', 450, DATE_FORMAT(NOW(), '%Y-%m-01') - INTERVAL 1 MONTH);
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX idx_subscriptions_price_id ON subscriptions;
+DROP INDEX idx_subscriptions_start_date_id ON subscriptions;
+DROP INDEX idx_subscriptions_service_id ON subscriptions;
@@ -0,0 +1,4 @@
+-- +migrate Up
+CREATE INDEX idx_subscriptions_price_id ON subscriptions (price, id);
+CREATE INDEX idx_subscriptions_start_date_id ON subscriptions (start_date, id);
+CREATE INDEX idx_subscriptions_service_id ON subscriptions (service_name, id);
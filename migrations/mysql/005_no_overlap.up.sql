@@ -0,0 +1,34 @@
+-- +migrate Up
+-- Подписки одного пользователя на один сервис не должны пересекаться по периоду.
+-- EXCLUDE-ограничений в MySQL нет, поэтому проверяем триггерами. В отличие от Postgres
+-- триггер не защищает от гонки двух параллельных вставок; основную проверку делает сервис.
+CREATE TRIGGER subscriptions_no_overlap_insert
+BEFORE INSERT ON subscriptions
+FOR EACH ROW
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM subscriptions s
+        WHERE s.user_id = NEW.user_id
+          AND s.service_name = NEW.service_name
+          AND s.start_date <= COALESCE(NEW.end_date, '9999-12-31')
+          AND COALESCE(s.end_date, '9999-12-31') >= NEW.start_date
+    ) THEN
+        SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'subscriptions_no_overlap';
+    END IF;
+END;
+
+CREATE TRIGGER subscriptions_no_overlap_update
+BEFORE UPDATE ON subscriptions
+FOR EACH ROW
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM subscriptions s
+        WHERE s.id <> NEW.id
+          AND s.user_id = NEW.user_id
+          AND s.service_name = NEW.service_name
+          AND s.start_date <= COALESCE(NEW.end_date, '9999-12-31')
+          AND COALESCE(s.end_date, '9999-12-31') >= NEW.start_date
+    ) THEN
+        SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'subscriptions_no_overlap';
+    END IF;
+END;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- Ответы на запросы с Idempotency-Key. status_code IS NULL — первый запрос ещё выполняется.
+-- Ключ с бинарной коллацией: ключи, различающиеся регистром, — разные ключи.
+CREATE TABLE idempotency_keys (
+                                  idempotency_key VARCHAR(255) COLLATE utf8mb4_bin PRIMARY KEY,
+                                  request_hash CHAR(64) NOT NULL,
+                                  status_code INT NULL,
+                                  response_headers TEXT NULL,
+                                  response_body MEDIUMBLOB NULL,
+                                  created_at DATETIME NOT NULL,
+                                  expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
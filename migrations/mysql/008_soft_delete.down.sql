@@ -0,0 +1,40 @@
+-- +migrate Down
+-- Удалённые подписки теряются: без deleted_at их не отличить от действующих.
+DELETE FROM subscriptions WHERE deleted_at IS NOT NULL;
+
+DROP TRIGGER subscriptions_no_overlap_insert;
+DROP TRIGGER subscriptions_no_overlap_update;
+
+CREATE TRIGGER subscriptions_no_overlap_insert
+BEFORE INSERT ON subscriptions
+FOR EACH ROW
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM subscriptions s
+        WHERE s.user_id = NEW.user_id
+          AND s.service_name = NEW.service_name
+          AND s.start_date <= COALESCE(NEW.end_date, '9999-12-31')
+          AND COALESCE(s.end_date, '9999-12-31') >= NEW.start_date
+    ) THEN
+        SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'subscriptions_no_overlap';
+    END IF;
+END;
+
+CREATE TRIGGER subscriptions_no_overlap_update
+BEFORE UPDATE ON subscriptions
+FOR EACH ROW
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM subscriptions s
+        WHERE s.id <> NEW.id
+          AND s.user_id = NEW.user_id
+          AND s.service_name = NEW.service_name
+          AND s.start_date <= COALESCE(NEW.end_date, '9999-12-31')
+          AND COALESCE(s.end_date, '9999-12-31') >= NEW.start_date
+    ) THEN
+        SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'subscriptions_no_overlap';
+    END IF;
+END;
+
+DROP INDEX idx_subscriptions_deleted_at ON subscriptions;
+ALTER TABLE subscriptions DROP COLUMN deleted_at;
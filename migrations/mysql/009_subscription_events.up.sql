@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Журнал изменений подписок, только на добавление. Пишется в одной транзакции с самим изменением.
+-- Внешнего ключа на subscriptions нет: история переживает окончательное удаление подписки.
+CREATE TABLE subscription_events (
+                                     id BIGINT AUTO_INCREMENT PRIMARY KEY,
+                                     subscription_id INT NOT NULL,
+                                     event_type VARCHAR(16) NOT NULL,
+                                     actor VARCHAR(255) NULL,
+                                     request_id VARCHAR(255) NULL,
+                                     before_state JSON NULL,
+                                     after_state JSON NULL,
+                                     created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_subscription_events_subscription_id ON subscription_events (subscription_id, id);
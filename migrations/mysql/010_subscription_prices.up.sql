@@ -0,0 +1,11 @@
+-- +migrate Up
+-- График цен: с месяца effective_from помесячная цена подписки — price.
+-- До первого изменения действует subscriptions.price.
+CREATE TABLE subscription_prices (
+                                     subscription_id INT NOT NULL,
+                                     effective_from DATE NOT NULL,
+                                     price INT NOT NULL,
+                                     PRIMARY KEY (subscription_id, effective_from),
+                                     CONSTRAINT fk_subscription_prices_subscription
+                                         FOREIGN KEY (subscription_id) REFERENCES subscriptions (id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+-- +migrate Down
+-- Цены в других валютах после отката читаются как рубли.
+DROP TABLE fx_rates;
+
+UPDATE subscription_prices SET price = price / 100;
+UPDATE subscriptions SET price = price / 100;
+ALTER TABLE subscription_prices MODIFY price INT NOT NULL;
+ALTER TABLE subscriptions MODIFY price INT NOT NULL;
+ALTER TABLE subscriptions DROP COLUMN currency;
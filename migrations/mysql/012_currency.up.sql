@@ -0,0 +1,18 @@
+-- +migrate Up
+-- Валюта подписки (ISO 4217). Цены теперь хранятся в минимальных единицах валюты (копейки, центы),
+-- до этого они были в целых рублях. В минимальных единицах цена перестаёт помещаться в INT
+-- уже с 21 474 837 рублей, поэтому колонки расширяются до BIGINT до умножения.
+ALTER TABLE subscriptions ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE subscriptions MODIFY price BIGINT NOT NULL;
+ALTER TABLE subscription_prices MODIFY price BIGINT NOT NULL;
+UPDATE subscriptions SET price = price * 100;
+UPDATE subscription_prices SET price = price * 100;
+
+-- Курсы валют: с даты valid_from одна единица base стоит rate единиц quote.
+CREATE TABLE fx_rates (
+                          base CHAR(3) NOT NULL,
+                          quote CHAR(3) NOT NULL,
+                          valid_from DATE NOT NULL,
+                          rate DECIMAL(20, 10) NOT NULL,
+                          PRIMARY KEY (base, quote, valid_from)
+);
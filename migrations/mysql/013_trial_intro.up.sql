@@ -0,0 +1,7 @@
+-- +migrate Up
+-- Пробный период (trial_count дней или месяцев с start_date, NULL — нет) и вводная цена
+-- (intro_price за первые intro_periods периодов списания после пробного, NULL — нет).
+ALTER TABLE subscriptions ADD COLUMN trial_unit VARCHAR(8) NULL;
+ALTER TABLE subscriptions ADD COLUMN trial_count INT NOT NULL DEFAULT 0;
+ALTER TABLE subscriptions ADD COLUMN intro_price BIGINT NULL;
+ALTER TABLE subscriptions ADD COLUMN intro_periods INT NOT NULL DEFAULT 0;
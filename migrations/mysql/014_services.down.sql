@@ -0,0 +1,4 @@
+-- +migrate Down
+-- Названия подписок остаются каноническими: исходные написания не сохранялись.
+DROP TABLE service_aliases;
+DROP TABLE services;
@@ -0,0 +1,46 @@
+-- +migrate Up
+-- Каталог сервисов: каноническое название и псевдонимы. name_key и alias_key — написание
+-- в нижнем регистре без пробелов по краям (model.ServiceKey), по ним названия сводятся к сервису.
+CREATE TABLE services (
+                          id INT AUTO_INCREMENT PRIMARY KEY,
+                          name VARCHAR(255) NOT NULL,
+                          name_key VARCHAR(255) NOT NULL UNIQUE,
+                          category VARCHAR(255) NOT NULL DEFAULT '',
+                          vendor_url VARCHAR(1024) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE service_aliases (
+                                 alias_key VARCHAR(255) PRIMARY KEY,
+                                 alias VARCHAR(255) NOT NULL,
+                                 service_id INT NOT NULL,
+                                 CONSTRAINT fk_service_aliases_service
+                                     FOREIGN KEY (service_id) REFERENCES services (id) ON DELETE CASCADE
+);
+
+-- Каталог заполняется существующими названиями, по сервису на ключ; каноническим становится
+-- наименьшее из написаний, его можно сменить через PUT /services/{id}.
+INSERT INTO services (name, name_key)
+SELECT MIN(TRIM(service_name)), LOWER(TRIM(service_name))
+FROM subscriptions
+GROUP BY LOWER(TRIM(service_name));
+
+-- Подписки переводятся на каноническое название. Подписки, которые после этого пересеклись бы
+-- по периоду с подпиской того же пользователя на другое написание того же сервиса, — дубли:
+-- они остаются как есть, чтобы миграция не упала на триггере subscriptions_no_overlap.
+-- Сравнение через BINARY: в регистронезависимой сопоставке «netflix» и «Netflix» равны.
+-- DISTINCT не даёт MySQL слить подзапрос с UPDATE той же таблицы.
+UPDATE subscriptions s
+    JOIN services c ON c.name_key = LOWER(TRIM(s.service_name))
+    LEFT JOIN (
+        SELECT DISTINCT a.id
+        FROM subscriptions a
+                 JOIN subscriptions b
+                      ON b.id <> a.id
+                          AND b.user_id = a.user_id
+                          AND LOWER(TRIM(b.service_name)) = LOWER(TRIM(a.service_name))
+                          AND b.start_date <= COALESCE(a.end_date, '9999-12-31')
+                          AND COALESCE(b.end_date, '9999-12-31') >= a.start_date
+    ) dup ON dup.id = s.id
+SET s.service_name = c.name, s.version = s.version + 1
+WHERE CAST(s.service_name AS BINARY) <> CAST(c.name AS BINARY)
+  AND dup.id IS NULL;
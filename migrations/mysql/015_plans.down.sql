@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE subscriptions DROP FOREIGN KEY fk_subscriptions_plan;
+ALTER TABLE subscriptions DROP COLUMN plan_id;
+DROP TABLE plans;
@@ -0,0 +1,18 @@
+-- +migrate Up
+-- Тарифы сервисов каталога с прейскурантной ценой за период списания.
+CREATE TABLE plans (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       service_id INT NOT NULL,
+                       name VARCHAR(255) NOT NULL,
+                       price BIGINT NOT NULL,
+                       currency CHAR(3) NOT NULL DEFAULT 'RUB',
+                       UNIQUE (service_id, name),
+                       CONSTRAINT fk_plans_service
+                           FOREIGN KEY (service_id) REFERENCES services (id) ON DELETE CASCADE
+);
+
+-- Тариф подписки; при удалении тарифа подписка остаётся с прежней ценой, но без тарифа.
+ALTER TABLE subscriptions ADD COLUMN plan_id INT NULL;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT fk_subscriptions_plan
+        FOREIGN KEY (plan_id) REFERENCES plans (id) ON DELETE SET NULL;
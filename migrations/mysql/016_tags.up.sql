@@ -0,0 +1,20 @@
+-- +migrate Up
+-- Статья расходов подписки (entertainment, productivity, ...) в нижнем регистре, пусто — не задана.
+ALTER TABLE subscriptions ADD COLUMN category VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX subscriptions_category_idx ON subscriptions (category);
+
+-- Свободные метки подписки в нижнем регистре.
+CREATE TABLE subscription_tags (
+                                   subscription_id INT NOT NULL,
+                                   tag VARCHAR(40) NOT NULL,
+                                   PRIMARY KEY (subscription_id, tag),
+                                   INDEX subscription_tags_tag_idx (tag),
+                                   CONSTRAINT fk_subscription_tags_subscription
+                                       FOREIGN KEY (subscription_id) REFERENCES subscriptions (id) ON DELETE CASCADE
+);
+
+-- Подписки на сервисы, у которых в каталоге уже есть категория, получают её.
+UPDATE subscriptions s
+    JOIN services c ON c.name = s.service_name
+SET s.category = LOWER(c.category), s.version = s.version + 1
+WHERE c.category <> '';
@@ -0,0 +1,29 @@
+-- +migrate Up
+-- Месячные бюджеты пользователей; пустая категория — бюджет на все подписки пользователя.
+CREATE TABLE budgets (
+                         id INT AUTO_INCREMENT PRIMARY KEY,
+                         user_id CHAR(36) NOT NULL,
+                         category VARCHAR(255) NOT NULL DEFAULT '',
+                         amount BIGINT NOT NULL,
+                         currency CHAR(3) NOT NULL DEFAULT 'RUB',
+                         UNIQUE (user_id, category)
+);
+
+-- Уведомления о достижении порога бюджета, по одному на бюджет, месяц и порог.
+-- Внешнего ключа на subscriptions нет: уведомление переживает удаление подписки.
+CREATE TABLE budget_alerts (
+                               id INT AUTO_INCREMENT PRIMARY KEY,
+                               budget_id INT NOT NULL,
+                               user_id CHAR(36) NOT NULL,
+                               category VARCHAR(255) NOT NULL DEFAULT '',
+                               month DATE NOT NULL,
+                               threshold INT NOT NULL,
+                               spent BIGINT NOT NULL,
+                               amount BIGINT NOT NULL,
+                               currency CHAR(3) NOT NULL,
+                               subscription_id INT NOT NULL,
+                               created_at DATETIME NOT NULL,
+                               UNIQUE (budget_id, month, threshold),
+                               CONSTRAINT fk_budget_alerts_budget
+                                   FOREIGN KEY (budget_id) REFERENCES budgets (id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_subscriptions_price_id;
+DROP INDEX IF EXISTS idx_subscriptions_start_date_id;
+DROP INDEX IF EXISTS idx_subscriptions_service_id;
@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_no_overlap;
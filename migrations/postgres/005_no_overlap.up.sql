@@ -0,0 +1,11 @@
+-- +migrate Up
+-- Подписки одного пользователя на один сервис не должны пересекаться по периоду.
+-- Ограничение закрывает гонку параллельных запросов, которую не ловит проверка в сервисе.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_no_overlap
+    EXCLUDE USING gist (
+        user_id WITH =,
+        service_name WITH =,
+        daterange(start_date, end_date, '[]') WITH &&
+    );
@@ -0,0 +1,3 @@
+-- +migrate Up
+-- Версия строки для оптимистичной блокировки: растёт на каждом UPDATE, клиенту отдаётся как ETag.
+ALTER TABLE subscriptions ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- +migrate Down
+DROP TABLE idempotency_keys;
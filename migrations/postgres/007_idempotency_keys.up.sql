@@ -0,0 +1,13 @@
+-- +migrate Up
+-- Ответы на запросы с Idempotency-Key. status_code IS NULL — первый запрос ещё выполняется.
+CREATE TABLE idempotency_keys (
+                                  idempotency_key VARCHAR(255) PRIMARY KEY,
+                                  request_hash CHAR(64) NOT NULL,
+                                  status_code INTEGER,
+                                  response_headers TEXT,
+                                  response_body BYTEA,
+                                  created_at TIMESTAMPTZ NOT NULL,
+                                  expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
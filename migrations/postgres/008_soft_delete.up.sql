@@ -0,0 +1,16 @@
+-- +migrate Up
+-- Мягкое удаление: удалённая подписка остаётся в таблице с deleted_at и может быть восстановлена.
+-- Окончательно такие строки удаляет фоновая очистка после истечения срока хранения.
+ALTER TABLE subscriptions ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_subscriptions_deleted_at ON subscriptions (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Удалённые подписки не мешают создавать новые на тот же период.
+ALTER TABLE subscriptions DROP CONSTRAINT subscriptions_no_overlap;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_no_overlap
+    EXCLUDE USING gist (
+        user_id WITH =,
+        service_name WITH =,
+        daterange(start_date, end_date, '[]') WITH &&
+    ) WHERE (deleted_at IS NULL);
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Журнал изменений подписок, только на добавление. Пишется в одной транзакции с самим изменением.
+-- Внешнего ключа на subscriptions нет: история переживает окончательное удаление подписки.
+CREATE TABLE subscription_events (
+                                     id BIGSERIAL PRIMARY KEY,
+                                     subscription_id INTEGER NOT NULL,
+                                     event_type VARCHAR(16) NOT NULL,
+                                     actor VARCHAR(255),
+                                     request_id VARCHAR(255),
+                                     before_state JSONB,
+                                     after_state JSONB,
+                                     created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_subscription_events_subscription_id ON subscription_events (subscription_id, id);
@@ -0,0 +1,5 @@
+-- +migrate Up
+-- Период списания: price — цена за billing_count единиц billing_unit (week, month, quarter, year).
+-- Существующие подписки остаются помесячными.
+ALTER TABLE subscriptions ADD COLUMN billing_unit VARCHAR(8) NOT NULL DEFAULT 'month';
+ALTER TABLE subscriptions ADD COLUMN billing_count INTEGER NOT NULL DEFAULT 1;
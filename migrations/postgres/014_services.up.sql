@@ -0,0 +1,41 @@
+-- +migrate Up
+-- Каталог сервисов: каноническое название и псевдонимы. name_key и alias_key — написание
+-- в нижнем регистре без пробелов по краям (model.ServiceKey), по ним названия сводятся к сервису.
+CREATE TABLE services (
+                          id SERIAL PRIMARY KEY,
+                          name VARCHAR(255) NOT NULL,
+                          name_key VARCHAR(255) NOT NULL UNIQUE,
+                          category VARCHAR(255) NOT NULL DEFAULT '',
+                          vendor_url VARCHAR(1024) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE service_aliases (
+                                 alias_key VARCHAR(255) PRIMARY KEY,
+                                 alias VARCHAR(255) NOT NULL,
+                                 service_id INTEGER NOT NULL REFERENCES services (id) ON DELETE CASCADE
+);
+CREATE INDEX service_aliases_service_id_idx ON service_aliases (service_id);
+
+-- Каталог заполняется существующими названиями, по сервису на ключ; каноническим становится
+-- наименьшее из написаний, его можно сменить через PUT /services/{id}.
+INSERT INTO services (name, name_key)
+SELECT MIN(TRIM(service_name)), LOWER(TRIM(service_name))
+FROM subscriptions
+GROUP BY LOWER(TRIM(service_name));
+
+-- Подписки переводятся на каноническое название. Подписки, которые после этого пересеклись бы
+-- по периоду с подпиской того же пользователя на другое написание того же сервиса, — дубли:
+-- они остаются как есть, чтобы миграция не упала на subscriptions_no_overlap.
+UPDATE subscriptions s
+SET service_name = c.name, version = s.version + 1
+FROM services c
+WHERE c.name_key = LOWER(TRIM(s.service_name))
+  AND s.service_name <> c.name
+  AND NOT EXISTS (
+    SELECT 1 FROM subscriptions o
+    WHERE o.id <> s.id
+      AND o.user_id = s.user_id
+      AND LOWER(TRIM(o.service_name)) = c.name_key
+      AND o.start_date <= COALESCE(s.end_date, '9999-12-31')
+      AND COALESCE(o.end_date, '9999-12-31') >= s.start_date
+);
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE subscriptions DROP COLUMN plan_id;
+DROP TABLE plans;
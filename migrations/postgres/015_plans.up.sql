@@ -0,0 +1,14 @@
+-- +migrate Up
+-- Тарифы сервисов каталога с прейскурантной ценой за период списания.
+CREATE TABLE plans (
+                       id SERIAL PRIMARY KEY,
+                       service_id INTEGER NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+                       name VARCHAR(255) NOT NULL,
+                       price BIGINT NOT NULL,
+                       currency CHAR(3) NOT NULL DEFAULT 'RUB',
+                       UNIQUE (service_id, name)
+);
+
+-- Тариф подписки; при удалении тарифа подписка остаётся с прежней ценой, но без тарифа.
+ALTER TABLE subscriptions ADD COLUMN plan_id INTEGER NULL REFERENCES plans (id) ON DELETE SET NULL;
+CREATE INDEX subscriptions_plan_id_idx ON subscriptions (plan_id);
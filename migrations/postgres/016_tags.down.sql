@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE subscription_tags;
+ALTER TABLE subscriptions DROP COLUMN category;
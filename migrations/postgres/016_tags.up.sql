@@ -0,0 +1,19 @@
+-- +migrate Up
+-- Статья расходов подписки (entertainment, productivity, ...) в нижнем регистре, пусто — не задана.
+ALTER TABLE subscriptions ADD COLUMN category VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX subscriptions_category_idx ON subscriptions (category);
+
+-- Свободные метки подписки в нижнем регистре.
+CREATE TABLE subscription_tags (
+                                   subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+                                   tag VARCHAR(40) NOT NULL,
+                                   PRIMARY KEY (subscription_id, tag)
+);
+CREATE INDEX subscription_tags_tag_idx ON subscription_tags (tag);
+
+-- Подписки на сервисы, у которых в каталоге уже есть категория, получают её.
+UPDATE subscriptions s
+SET category = LOWER(c.category), version = s.version + 1
+FROM services c
+WHERE c.name = s.service_name
+  AND c.category <> '';
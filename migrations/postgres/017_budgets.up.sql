@@ -0,0 +1,27 @@
+-- +migrate Up
+-- Месячные бюджеты пользователей; пустая категория — бюджет на все подписки пользователя.
+CREATE TABLE budgets (
+                         id SERIAL PRIMARY KEY,
+                         user_id UUID NOT NULL,
+                         category VARCHAR(255) NOT NULL DEFAULT '',
+                         amount BIGINT NOT NULL,
+                         currency CHAR(3) NOT NULL DEFAULT 'RUB',
+                         UNIQUE (user_id, category)
+);
+
+-- Уведомления о достижении порога бюджета, по одному на бюджет, месяц и порог.
+-- Внешнего ключа на subscriptions нет: уведомление переживает удаление подписки.
+CREATE TABLE budget_alerts (
+                               id SERIAL PRIMARY KEY,
+                               budget_id INTEGER NOT NULL REFERENCES budgets (id) ON DELETE CASCADE,
+                               user_id UUID NOT NULL,
+                               category VARCHAR(255) NOT NULL DEFAULT '',
+                               month DATE NOT NULL,
+                               threshold INTEGER NOT NULL,
+                               spent BIGINT NOT NULL,
+                               amount BIGINT NOT NULL,
+                               currency CHAR(3) NOT NULL,
+                               subscription_id INTEGER NOT NULL,
+                               created_at TIMESTAMPTZ NOT NULL,
+                               UNIQUE (budget_id, month, threshold)
+);
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- Даты хранятся строками YYYY-MM-DD: так они корректно сравниваются и читаются драйвером как DATE.
+CREATE TABLE subscriptions (
+                               id INTEGER PRIMARY KEY AUTOINCREMENT,
+                               service_name TEXT NOT NULL,
+                               price INTEGER NOT NULL,
+                               user_id TEXT NOT NULL,
+                               start_date DATE NOT NULL,
+                               end_date DATE
+);
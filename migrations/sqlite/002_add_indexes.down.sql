@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_subscriptions_user;
+DROP INDEX IF EXISTS idx_subscriptions_service;
+DROP INDEX IF EXISTS idx_subscriptions_period;
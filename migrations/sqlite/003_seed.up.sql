@@ -0,0 +1,5 @@
+-- +migrate Up
+INSERT INTO subscriptions (user_id, service_name, price, start_date)
+VALUES
+    ('11111111-1111-1111-1111-111111111111', 'netflix', 800, date('now', 'start of month', '-2 months')),
+    ('22222222-2222-2222-2222-222222222222', 'spotify', 450, date('now', 'start of month', '-1 month'));
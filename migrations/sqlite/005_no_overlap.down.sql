@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TRIGGER IF EXISTS subscriptions_no_overlap_insert;
+DROP TRIGGER IF EXISTS subscriptions_no_overlap_update;
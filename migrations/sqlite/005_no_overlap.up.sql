@@ -0,0 +1,30 @@
+-- +migrate Up
+-- Подписки одного пользователя на один сервис не должны пересекаться по периоду.
+-- EXCLUDE-ограничений в SQLite нет, поэтому проверяем триггерами; записи в SQLite
+-- сериализуются, так что параллельные запросы проверку не обойдут.
+CREATE TRIGGER subscriptions_no_overlap_insert
+BEFORE INSERT ON subscriptions
+WHEN EXISTS (
+    SELECT 1 FROM subscriptions s
+    WHERE s.user_id = NEW.user_id
+      AND s.service_name = NEW.service_name
+      AND s.start_date <= COALESCE(NEW.end_date, '9999-12-31')
+      AND COALESCE(s.end_date, '9999-12-31') >= NEW.start_date
+)
+BEGIN
+    SELECT RAISE(ABORT, 'subscriptions_no_overlap');
+END;
+
+CREATE TRIGGER subscriptions_no_overlap_update
+BEFORE UPDATE ON subscriptions
+WHEN EXISTS (
+    SELECT 1 FROM subscriptions s
+    WHERE s.id <> NEW.id
+      AND s.user_id = NEW.user_id
+      AND s.service_name = NEW.service_name
+      AND s.start_date <= COALESCE(NEW.end_date, '9999-12-31')
+      AND COALESCE(s.end_date, '9999-12-31') >= NEW.start_date
+)
+BEGIN
+    SELECT RAISE(ABORT, 'subscriptions_no_overlap');
+END;
@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE subscriptions DROP COLUMN version;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- Ответы на запросы с Idempotency-Key. status_code IS NULL — первый запрос ещё выполняется.
+-- Время хранится строками YYYY-MM-DD HH:MM:SS в UTC, чтобы сравнение строк совпадало со сравнением времени.
+CREATE TABLE idempotency_keys (
+                                  idempotency_key TEXT PRIMARY KEY,
+                                  request_hash TEXT NOT NULL,
+                                  status_code INTEGER,
+                                  response_headers TEXT,
+                                  response_body BLOB,
+                                  created_at DATETIME NOT NULL,
+                                  expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,39 @@
+-- +migrate Up
+-- Мягкое удаление: удалённая подписка остаётся в таблице с deleted_at и может быть восстановлена.
+-- Окончательно такие строки удаляет фоновая очистка после истечения срока хранения.
+ALTER TABLE subscriptions ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX idx_subscriptions_deleted_at ON subscriptions (deleted_at);
+
+-- Удалённые подписки не мешают создавать новые на тот же период.
+DROP TRIGGER subscriptions_no_overlap_insert;
+DROP TRIGGER subscriptions_no_overlap_update;
+
+CREATE TRIGGER subscriptions_no_overlap_insert
+BEFORE INSERT ON subscriptions
+WHEN NEW.deleted_at IS NULL AND EXISTS (
+    SELECT 1 FROM subscriptions s
+    WHERE s.deleted_at IS NULL
+      AND s.user_id = NEW.user_id
+      AND s.service_name = NEW.service_name
+      AND s.start_date <= COALESCE(NEW.end_date, '9999-12-31')
+      AND COALESCE(s.end_date, '9999-12-31') >= NEW.start_date
+)
+BEGIN
+    SELECT RAISE(ABORT, 'subscriptions_no_overlap');
+END;
+
+CREATE TRIGGER subscriptions_no_overlap_update
+BEFORE UPDATE ON subscriptions
+WHEN NEW.deleted_at IS NULL AND EXISTS (
+    SELECT 1 FROM subscriptions s
+    WHERE s.id <> NEW.id
+      AND s.deleted_at IS NULL
+      AND s.user_id = NEW.user_id
+      AND s.service_name = NEW.service_name
+      AND s.start_date <= COALESCE(NEW.end_date, '9999-12-31')
+      AND COALESCE(s.end_date, '9999-12-31') >= NEW.start_date
+)
+BEGIN
+    SELECT RAISE(ABORT, 'subscriptions_no_overlap');
+END;
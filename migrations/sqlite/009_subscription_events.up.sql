@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Журнал изменений подписок, только на добавление. Пишется в одной транзакции с самим изменением.
+-- Внешнего ключа на subscriptions нет: история переживает окончательное удаление подписки.
+CREATE TABLE subscription_events (
+                                     id INTEGER PRIMARY KEY AUTOINCREMENT,
+                                     subscription_id INTEGER NOT NULL,
+                                     event_type TEXT NOT NULL,
+                                     actor TEXT,
+                                     request_id TEXT,
+                                     before_state TEXT,
+                                     after_state TEXT,
+                                     created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_subscription_events_subscription_id ON subscription_events (subscription_id, id);
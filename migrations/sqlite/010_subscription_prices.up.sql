@@ -0,0 +1,9 @@
+-- +migrate Up
+-- График цен: с месяца effective_from помесячная цена подписки — price.
+-- До первого изменения действует subscriptions.price.
+CREATE TABLE subscription_prices (
+                                     subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+                                     effective_from DATE NOT NULL,
+                                     price INTEGER NOT NULL,
+                                     PRIMARY KEY (subscription_id, effective_from)
+);
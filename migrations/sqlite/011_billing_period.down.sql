@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE subscriptions DROP COLUMN billing_count;
+ALTER TABLE subscriptions DROP COLUMN billing_unit;
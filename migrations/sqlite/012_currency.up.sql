@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Валюта подписки (ISO 4217). Цены теперь хранятся в минимальных единицах валюты (копейки, центы),
+-- до этого они были в целых рублях. INTEGER в SQLite 64-битный, расширять колонки не нужно.
+ALTER TABLE subscriptions ADD COLUMN currency TEXT NOT NULL DEFAULT 'RUB';
+UPDATE subscriptions SET price = price * 100;
+UPDATE subscription_prices SET price = price * 100;
+
+-- Курсы валют: с даты valid_from одна единица base стоит rate единиц quote.
+CREATE TABLE fx_rates (
+                          base TEXT NOT NULL,
+                          quote TEXT NOT NULL,
+                          valid_from DATE NOT NULL,
+                          rate REAL NOT NULL,
+                          PRIMARY KEY (base, quote, valid_from)
+);
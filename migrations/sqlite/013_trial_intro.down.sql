@@ -0,0 +1,5 @@
+-- +migrate Down
+ALTER TABLE subscriptions DROP COLUMN intro_periods;
+ALTER TABLE subscriptions DROP COLUMN intro_price;
+ALTER TABLE subscriptions DROP COLUMN trial_count;
+ALTER TABLE subscriptions DROP COLUMN trial_unit;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- Пробный период (trial_count дней или месяцев с start_date, NULL — нет) и вводная цена
+-- (intro_price за первые intro_periods периодов списания после пробного, NULL — нет).
+ALTER TABLE subscriptions ADD COLUMN trial_unit TEXT NULL;
+ALTER TABLE subscriptions ADD COLUMN trial_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscriptions ADD COLUMN intro_price INTEGER NULL;
+ALTER TABLE subscriptions ADD COLUMN intro_periods INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,42 @@
+-- +migrate Up
+-- Каталог сервисов: каноническое название и псевдонимы. name_key и alias_key — написание
+-- в нижнем регистре без пробелов по краям (model.ServiceKey), по ним названия сводятся к сервису.
+-- LOWER в SQLite понижает только ASCII, поэтому названия не на латинице при заполнении
+-- каталога могут не совпасть с ключом из сервиса; их стоит проверить вручную.
+CREATE TABLE services (
+                          id INTEGER PRIMARY KEY AUTOINCREMENT,
+                          name TEXT NOT NULL,
+                          name_key TEXT NOT NULL UNIQUE,
+                          category TEXT NOT NULL DEFAULT '',
+                          vendor_url TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE service_aliases (
+                                 alias_key TEXT PRIMARY KEY,
+                                 alias TEXT NOT NULL,
+                                 service_id INTEGER NOT NULL REFERENCES services (id) ON DELETE CASCADE
+);
+CREATE INDEX service_aliases_service_id_idx ON service_aliases (service_id);
+
+-- Каталог заполняется существующими названиями, по сервису на ключ; каноническим становится
+-- наименьшее из написаний, его можно сменить через PUT /services/{id}.
+INSERT INTO services (name, name_key)
+SELECT MIN(TRIM(service_name)), LOWER(TRIM(service_name))
+FROM subscriptions
+GROUP BY LOWER(TRIM(service_name));
+
+-- Подписки переводятся на каноническое название. Подписки, которые после этого пересеклись бы
+-- по периоду с подпиской того же пользователя на другое написание того же сервиса, — дубли:
+-- они остаются как есть, чтобы миграция не упала на триггере subscriptions_no_overlap.
+UPDATE subscriptions
+SET service_name = (SELECT c.name FROM services c WHERE c.name_key = LOWER(TRIM(subscriptions.service_name))),
+    version = version + 1
+WHERE service_name <> (SELECT c.name FROM services c WHERE c.name_key = LOWER(TRIM(subscriptions.service_name)))
+  AND NOT EXISTS (
+    SELECT 1 FROM subscriptions o
+    WHERE o.id <> subscriptions.id
+      AND o.user_id = subscriptions.user_id
+      AND LOWER(TRIM(o.service_name)) = LOWER(TRIM(subscriptions.service_name))
+      AND o.start_date <= COALESCE(subscriptions.end_date, '9999-12-31')
+      AND COALESCE(o.end_date, '9999-12-31') >= subscriptions.start_date
+);
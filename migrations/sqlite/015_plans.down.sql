@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX subscriptions_plan_id_idx;
+ALTER TABLE subscriptions DROP COLUMN plan_id;
+DROP TABLE plans;
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP TABLE subscription_tags;
+DROP INDEX subscriptions_category_idx;
+ALTER TABLE subscriptions DROP COLUMN category;
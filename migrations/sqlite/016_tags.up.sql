@@ -0,0 +1,22 @@
+-- +migrate Up
+-- Статья расходов подписки (entertainment, productivity, ...) в нижнем регистре, пусто — не задана.
+ALTER TABLE subscriptions ADD COLUMN category TEXT NOT NULL DEFAULT '';
+CREATE INDEX subscriptions_category_idx ON subscriptions (category);
+
+-- Свободные метки подписки в нижнем регистре.
+CREATE TABLE subscription_tags (
+                                   subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+                                   tag TEXT NOT NULL,
+                                   PRIMARY KEY (subscription_id, tag)
+);
+CREATE INDEX subscription_tags_tag_idx ON subscription_tags (tag);
+
+-- Подписки на сервисы, у которых в каталоге уже есть категория, получают её.
+UPDATE subscriptions
+SET category = (SELECT LOWER(c.category) FROM services c WHERE c.name = subscriptions.service_name),
+    version = version + 1
+WHERE EXISTS (
+    SELECT 1 FROM services c
+    WHERE c.name = subscriptions.service_name
+      AND c.category <> ''
+);
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE budget_alerts;
+DROP TABLE budgets;
@@ -0,0 +1,27 @@
+-- +migrate Up
+-- Месячные бюджеты пользователей; пустая категория — бюджет на все подписки пользователя.
+CREATE TABLE budgets (
+                         id INTEGER PRIMARY KEY AUTOINCREMENT,
+                         user_id TEXT NOT NULL,
+                         category TEXT NOT NULL DEFAULT '',
+                         amount INTEGER NOT NULL,
+                         currency TEXT NOT NULL DEFAULT 'RUB',
+                         UNIQUE (user_id, category)
+);
+
+-- Уведомления о достижении порога бюджета, по одному на бюджет, месяц и порог.
+-- Внешнего ключа на subscriptions нет: уведомление переживает удаление подписки.
+CREATE TABLE budget_alerts (
+                               id INTEGER PRIMARY KEY AUTOINCREMENT,
+                               budget_id INTEGER NOT NULL REFERENCES budgets (id) ON DELETE CASCADE,
+                               user_id TEXT NOT NULL,
+                               category TEXT NOT NULL DEFAULT '',
+                               month DATE NOT NULL,
+                               threshold INTEGER NOT NULL,
+                               spent INTEGER NOT NULL,
+                               amount INTEGER NOT NULL,
+                               currency TEXT NOT NULL,
+                               subscription_id INTEGER NOT NULL,
+                               created_at DATETIME NOT NULL,
+                               UNIQUE (budget_id, month, threshold)
+);